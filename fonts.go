@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"tablemaker/output"
+	"tablemaker/tables"
+)
+
+// runFonts runs the fonts subcommand, which inspects the fonts available
+// to PNG output and manages the font index
+func runFonts(args []string) {
+	flags := flag.NewFlagSet("fonts", flag.ExitOnError)
+	rebuild := flags.Bool("rebuild", false, "Read every font directory again and replace the font index cache")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s fonts [--rebuild] <command>\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  list [--mono]    List the discovered fonts\n")
+		fmt.Fprintf(os.Stderr, "  find <name>      Show how a font name or path resolves\n")
+		fmt.Fprintf(os.Stderr, "  check <config>   Check that the configured PNG fonts cover the table's characters\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *rebuild {
+		idx, path, err := output.RebuildFontIndex()
+		if err != nil {
+			log.Fatalf("Error rebuilding font index: %v", err)
+		}
+		fmt.Printf("Indexed %d fonts\nCache: %s\n", len(idx.Fonts), path)
+		if flags.NArg() == 0 {
+			return
+		}
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "list":
+		fontsList(rest)
+	case "find":
+		fontsFind(rest)
+	case "check":
+		fontsCheck(rest)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown fonts command %q\n", command)
+		flags.Usage()
+		os.Exit(1)
+	}
+}
+
+// fontsList prints a table of the discovered fonts
+func fontsList(args []string) {
+	flags := flag.NewFlagSet("fonts list", flag.ExitOnError)
+	mono := flags.Bool("mono", false, "List monospaced fonts only")
+	flags.Parse(args)
+
+	fonts := output.SystemFonts()
+	sort.SliceStable(fonts, func(i, j int) bool {
+		if fonts[i].Family != fonts[j].Family {
+			return fonts[i].Family < fonts[j].Family
+		}
+		return fonts[i].Style < fonts[j].Style
+	})
+
+	table := tables.NewTable().Headers("Family", "Style", "Monospace", "Path")
+	for _, f := range fonts {
+		if *mono && !f.Monospace {
+			continue
+		}
+		monospace := ""
+		if f.Monospace {
+			monospace = "yes"
+		}
+		table.AddRow(f.Family, f.Style, monospace, f.Spec())
+	}
+	table.EmptyText("No fonts found")
+
+	if err := table.Render(os.Stdout); err != nil {
+		log.Fatalf("Error rendering font list: %v", err)
+	}
+}
+
+// fontsFind prints how a font name or path resolves
+func fontsFind(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s fonts find <name>\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	lookup, err := output.FindFont(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f := lookup.Font
+	fmt.Printf("Name:      %s\n", args[0])
+	if lookup.Alias != "" {
+		fmt.Printf("Alias:     %s (not installed, resolved through its alias)\n", lookup.Alias)
+	}
+	fmt.Printf("Matched:   %s\n", lookup.Variation)
+	fmt.Printf("Font:      %s\n", f.Name())
+	fmt.Printf("Family:    %s\n", f.Family)
+	fmt.Printf("Style:     %s\n", f.Style)
+	fmt.Printf("Weight:    %d\n", f.Weight)
+	fmt.Printf("Monospace: %t\n", f.Monospace)
+	fmt.Printf("Path:      %s\n", f.Spec())
+}
+
+// fontsCheck reports whether the PNG fonts of a table configuration cover
+// every character of the table, exiting with status 1 when they do not
+func fontsCheck(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s fonts check <config>\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		log.Fatalf("Error reading input file: %v", err)
+	}
+	var config tables.TableConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Fatalf("Error parsing JSON: %v", err)
+	}
+
+	// Render the table as PNG output would
+	renderer, err := tables.GetRenderer(config.Type)
+	if err != nil {
+		log.Fatalf("Error getting renderer: %v", err)
+	}
+	if _, isHTML := renderer.(*tables.HTMLRenderer); isHTML {
+		log.Fatal("HTML output cannot be rendered as PNG")
+	}
+	tableText, err := tables.RenderString(renderer, config)
+	if err != nil {
+		log.Fatalf("Error rendering table: %v", err)
+	}
+
+	var pngConfig output.PNGConfig
+	if config.PNG != nil {
+		pngConfig = *config.PNG
+	}
+	coverage, err := output.CheckFonts(tableText, pngConfig)
+	if err != nil {
+		log.Fatalf("Error loading fonts: %v", err)
+	}
+
+	fmt.Printf("ascii_font: %s (%d characters)\n", coverage.Paths[0], len(coverage.Glyphs[0]))
+	for i, path := range coverage.Paths[1:] {
+		glyphs := coverage.Glyphs[i+1]
+		if len(glyphs) == 0 {
+			// The embedded font ends every chain; only mention it when used
+			if path != output.EmbeddedFont {
+				fmt.Printf("  fallback %s: unused\n", path)
+			}
+			continue
+		}
+		fmt.Printf("  fallback %s: %d characters: %s\n", path, len(glyphs), formatRunes(glyphs))
+	}
+	if len(coverage.Missing) == 0 {
+		fmt.Printf("  all characters covered\n")
+		return
+	}
+	fmt.Printf("  missing %d characters: %s\n", len(coverage.Missing), formatRunes(coverage.Missing))
+	os.Exit(1)
+}
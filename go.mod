@@ -2,7 +2,6 @@ module tablemaker
 
 go 1.24.2
 
-require (
-	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
-	golang.org/x/image v0.28.0
-)
+require golang.org/x/image v0.28.0
+
+require golang.org/x/text v0.26.0 // indirect
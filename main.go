@@ -1,27 +1,50 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"tablemaker/output"
 	"tablemaker/tables"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fonts" {
+		runFonts(os.Args[2:])
+		return
+	}
+
 	var (
 		inputFile  = flag.String("input", "", "Input JSON file path (required)")
 		outputFile = flag.String("out", "", "Output file path (optional, defaults to stdout)")
 		pngOutput  = flag.Bool("png", false, "Generate PNG output instead of text")
+		pngScale   = flag.String("scale", "", "PNG scale factor, e.g. 2 for @2x, or a list such as 1,2,3 to write one image per scale")
+		dataFile   = flag.String("data", "", "CSV or NDJSON file supplying the table rows (\"-\" for stdin)")
+		dataFormat = flag.String("data-format", "", "Data file format: csv or ndjson (default: from file extension)")
+		stream     = flag.Bool("stream", false, "Stream rows from -data instead of loading them all")
+		sampleSize = flag.Int("sample", tables.DefaultSampleSize, "Rows sampled to size columns when streaming")
+		widths     = flag.String("widths", "", "Comma-separated fixed column widths when streaming")
+		columns    = flag.String("columns", "", "Columns to show, in order, e.g. \"Database,Connections=Conns\" (overrides the config)")
+		where      = flag.String("where", "", "Keep rows matching an expression, e.g. 'Status != \"Offline\"' (overrides the config)")
+		sortSpec   = flag.String("sort", "", "Sort rows by columns, e.g. \"Connections:desc,Database\" (overrides the config)")
+		transpose  = flag.Bool("transpose", false, "Render each record vertically as field/value rows")
+		htmlOutput = flag.Bool("html", false, "Generate an HTML table instead of text")
+		colorMode  = flag.String("color", "auto", "Draw cell styles with ANSI colors: auto, always or never")
+		hermetic   = flag.Bool("hermetic", false, "Render PNG output with the embedded font only, for identical images on every machine")
 	)
 	flag.Parse()
 
 	if *inputFile == "" {
-		fmt.Fprintf(os.Stderr, "Usage: %s -input <json_file> [-out <output_file>] [-png]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s -input <json_file> [-data <rows_file> [-stream]] [-out <output_file>] [-png]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s fonts [--rebuild] list|find|check\n", os.Args[0])
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
@@ -37,21 +60,121 @@ func main() {
 		log.Fatalf("Error parsing JSON: %v", err)
 	}
 
-	// Generate table text
+	if *columns != "" {
+		specs, err := tables.ParseColumns(*columns)
+		if err != nil {
+			log.Fatalf("Error parsing columns: %v", err)
+		}
+		config.Columns = tables.OverrideColumns(config.Columns, specs)
+	}
+	if *where != "" {
+		config.Filter = *where
+	}
+	if *sortSpec != "" {
+		config.Sort = *sortSpec
+	}
+	if *transpose {
+		config.Transpose = true
+	}
+
+	// Look up the renderer for the table type
 	renderer, err := tables.GetRenderer(config.Type)
 	if err != nil {
 		log.Fatalf("Error getting renderer: %v", err)
 	}
+	if *htmlOutput {
+		renderer = &tables.HTMLRenderer{}
+	}
+	if _, isHTML := renderer.(*tables.HTMLRenderer); isHTML && *pngOutput {
+		log.Fatal("HTML output cannot be rendered as PNG")
+	}
+
+	if ascii, ok := renderer.(*tables.ASCIITableRenderer); ok {
+		color, err := useColor(*colorMode, *outputFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ascii.ANSI = color && !*pngOutput
+	}
+
+	// Load rows from a separate data file
+	var rows tables.RowReader
+	if *dataFile != "" {
+		in := os.Stdin
+		if *dataFile != "-" {
+			f, err := os.Open(*dataFile)
+			if err != nil {
+				log.Fatalf("Error reading data file: %v", err)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		// Configured headers name the columns; otherwise the file provides them
+		var headers []string
+		if len(config.Headers) > 0 {
+			headers = config.Headers
+		}
+		rows, err = tables.NewRowReader(bufio.NewReader(in), *dataFormat, *dataFile, headers)
+		if err != nil {
+			log.Fatalf("Error reading data file: %v", err)
+		}
+
+		if !*stream {
+			headers, all, err := tables.ReadAllRows(rows)
+			if err != nil {
+				log.Fatalf("Error reading data file: %v", err)
+			}
+			config.Headers = headers
+			config.Rows = tables.TextRows(all)
+			rows = nil
+		}
+	} else if *stream {
+		log.Fatal("-stream requires -data")
+	}
+
+	if rows != nil {
+		if *pngOutput {
+			log.Fatal("PNG output cannot be streamed")
+		}
+
+		streamer, ok := renderer.(tables.StreamRenderer)
+		if !ok {
+			log.Fatalf("Table type %s does not support streaming", config.Type)
+		}
 
-	tableText := renderer.Render(config)
-	if tableText == "" {
-		log.Fatal("Generated table is empty")
+		opts := tables.StreamOptions{SampleSize: *sampleSize}
+		if *widths != "" {
+			for _, field := range strings.Split(*widths, ",") {
+				width, err := strconv.Atoi(strings.TrimSpace(field))
+				if err != nil {
+					log.Fatalf("Invalid column width %q", field)
+				}
+				opts.Widths = append(opts.Widths, width)
+			}
+		}
+
+		writeText(*outputFile, func(w io.Writer) error {
+			return streamer.Stream(w, config, rows, opts)
+		})
+		return
 	}
 
 	// Handle output
 	if *pngOutput {
 		if config.PNG == nil {
-			log.Fatal("PNG configuration not found in JSON file")
+			if !*hermetic {
+				log.Fatal("PNG configuration not found in JSON file")
+			}
+			config.PNG = &output.PNGConfig{}
+		}
+		if *hermetic {
+			config.PNG.Hermetic = true
+		}
+
+		tableText, spans, err := tables.RenderStyledString(renderer, config)
+		if err != nil {
+			log.Fatalf("Error rendering table: %v", err)
 		}
 
 		pngPath := *outputFile
@@ -59,21 +182,138 @@ func main() {
 			pngPath = "output.png"
 		}
 
-		if err := output.GeneratePNG(tableText, *config.PNG, pngPath); err != nil {
+		if *pngScale != "" {
+			scales, err := parseScales(*pngScale)
+			if err != nil {
+				log.Fatal(err)
+			}
+			config.PNG.Scale, config.PNG.Scales = 0, nil
+			if len(scales) == 1 {
+				config.PNG.Scale = scales[0]
+			} else {
+				config.PNG.Scales = scales
+			}
+		}
+
+		result, err := output.GeneratePNGSet(tableText, spans, *config.PNG, pngPath)
+		if err != nil {
 			log.Fatalf("Error generating PNG: %v", err)
 		}
 
-		fmt.Printf("PNG generated: %s\n", pngPath)
+		if len(result.Missing) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: no font can render %d character(s): %s\n",
+				len(result.Missing), formatRunes(result.Missing))
+		}
+		for _, path := range result.Paths {
+			fmt.Printf("PNG generated: %s\n", path)
+		}
 		return
 	}
 
 	// Text output
-	if *outputFile != "" {
-		if err := ioutil.WriteFile(*outputFile, []byte(tableText), 0644); err != nil {
+	writeText(*outputFile, func(w io.Writer) error {
+		return renderer.Render(w, config)
+	})
+}
+
+// writeText runs render against the output file, or stdout when no file is given
+func writeText(outputFile string, render func(w io.Writer) error) {
+	var out io.Writer = os.Stdout
+	file := &outputFileWriter{path: outputFile}
+	if outputFile != "" {
+		out = file
+	}
+
+	buffered := bufio.NewWriter(out)
+	if err := render(buffered); err != nil {
+		file.remove()
+		log.Fatalf("Error rendering table: %v", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		file.remove()
+		log.Fatalf("Error writing output: %v", err)
+	}
+
+	if outputFile != "" {
+		if err := file.close(); err != nil {
 			log.Fatalf("Error writing output file: %v", err)
 		}
-		fmt.Printf("Output written to: %s\n", *outputFile)
-	} else {
-		fmt.Print(tableText)
+		fmt.Printf("Output written to: %s\n", outputFile)
+	}
+}
+
+// outputFileWriter creates its file on the first write, so that a table
+// that fails to render before any output leaves no file behind
+type outputFileWriter struct {
+	path string
+	file *os.File
+}
+
+func (w *outputFileWriter) Write(p []byte) (int, error) {
+	if w.file == nil {
+		file, err := os.Create(w.path)
+		if err != nil {
+			return 0, err
+		}
+		w.file = file
+	}
+	return w.file.Write(p)
+}
+
+// close closes the file, creating it when nothing was written
+func (w *outputFileWriter) close() error {
+	if w.file == nil {
+		if _, err := w.Write(nil); err != nil {
+			return err
+		}
+	}
+	return w.file.Close()
+}
+
+// remove deletes a partially written file
+func (w *outputFileWriter) remove() {
+	if w.file != nil {
+		w.file.Close()
+		os.Remove(w.path)
+	}
+}
+
+// useColor decides whether text output gets ANSI colors. In auto mode
+// colors are used when writing to a terminal and NO_COLOR is not set.
+func useColor(mode, outputFile string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if outputFile != "" || os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	}
+	return false, fmt.Errorf("invalid -color %q: use auto, always or never", mode)
+}
+
+// parseScales parses a comma-separated list of PNG scale factors
+func parseScales(list string) ([]float64, error) {
+	var scales []float64
+	for _, field := range strings.Split(list, ",") {
+		scale, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || scale <= 0 {
+			return nil, fmt.Errorf("invalid -scale %q", field)
+		}
+		scales = append(scales, scale)
+	}
+	return scales, nil
+}
+
+// formatRunes lists characters with their code points, e.g. "★ (U+2605)"
+func formatRunes(runes []rune) string {
+	parts := make([]string, len(runes))
+	for i, r := range runes {
+		parts[i] = fmt.Sprintf("%c (U+%04X)", r, r)
 	}
+	return strings.Join(parts, ", ")
 }
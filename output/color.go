@@ -0,0 +1,159 @@
+package output
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// ansiPalette holds the 16 standard ANSI colors: black, red, green, yellow,
+// blue, magenta, cyan and white, followed by their bright variants
+var ansiPalette = [16]color.RGBA{
+	{0, 0, 0, 255},
+	{205, 49, 49, 255},
+	{13, 188, 121, 255},
+	{229, 229, 16, 255},
+	{36, 114, 200, 255},
+	{188, 63, 188, 255},
+	{17, 168, 205, 255},
+	{229, 229, 229, 255},
+	{102, 102, 102, 255},
+	{241, 76, 76, 255},
+	{35, 209, 139, 255},
+	{245, 245, 67, 255},
+	{59, 142, 234, 255},
+	{214, 112, 214, 255},
+	{41, 184, 219, 255},
+	{255, 255, 255, 255},
+}
+
+// ansiColorNames are the names of the ANSI palette entries, in palette order
+var ansiColorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// extraColors are named colors outside the ANSI palette
+var extraColors = map[string]color.RGBA{
+	"gray":   ansiPalette[8],
+	"grey":   ansiPalette[8],
+	"orange": {255, 140, 0, 255},
+	"purple": {128, 0, 128, 255},
+	"pink":   {255, 105, 180, 255},
+}
+
+// ANSIColorIndex returns the palette index of a named ANSI color such as
+// "red" or "bright-red"
+func ANSIColorIndex(name string) (int, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	offset := 0
+	if rest, ok := strings.CutPrefix(name, "bright-"); ok {
+		name, offset = rest, 8
+	}
+	for i, n := range ansiColorNames {
+		if n == name {
+			return i + offset, true
+		}
+	}
+	return 0, false
+}
+
+// ParseColor parses a color name ("red", "bright-blue", "orange",
+// "transparent"), a hex color ("#f00", "#ff0000", "#ff000080") or a CSS
+// style rgb()/rgba() color ("rgba(255, 0, 0, 0.5)")
+func ParseColor(s string) (color.RGBA, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	if i, ok := ANSIColorIndex(text); ok {
+		return ansiPalette[i], nil
+	}
+	if c, ok := extraColors[text]; ok {
+		return c, nil
+	}
+	if text == "transparent" {
+		return color.RGBA{}, nil
+	}
+	if c, ok := parseRGBFunc(text); ok {
+		return c, nil
+	}
+
+	if hex, ok := strings.CutPrefix(text, "#"); ok {
+		if len(hex) == 3 || len(hex) == 4 {
+			var expanded strings.Builder
+			for _, r := range hex {
+				expanded.WriteRune(r)
+				expanded.WriteRune(r)
+			}
+			hex = expanded.String()
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		if len(hex) == 8 {
+			if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+				c := color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}
+				return color.RGBAModel.Convert(c).(color.RGBA), nil
+			}
+		}
+	}
+
+	return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+}
+
+// parseRGBFunc parses rgb(r, g, b) and rgba(r, g, b, a). Channels are
+// 0-255 and alpha is 0-1 or a percentage. The result is premultiplied, as
+// color.RGBA requires.
+func parseRGBFunc(text string) (color.RGBA, bool) {
+	args, ok := strings.CutPrefix(text, "rgba(")
+	if !ok {
+		args, ok = strings.CutPrefix(text, "rgb(")
+	}
+	args, closed := strings.CutSuffix(args, ")")
+	if !ok || !closed {
+		return color.RGBA{}, false
+	}
+
+	fields := strings.Split(args, ",")
+	if len(fields) != 3 && len(fields) != 4 {
+		return color.RGBA{}, false
+	}
+
+	var channels [3]float64
+	for i := range channels {
+		v, err := strconv.ParseFloat(strings.TrimSpace(fields[i]), 64)
+		if err != nil || v < 0 || v > 255 {
+			return color.RGBA{}, false
+		}
+		channels[i] = v
+	}
+
+	alpha := 1.0
+	if len(fields) == 4 {
+		field := strings.TrimSpace(fields[3])
+		percent := strings.HasSuffix(field, "%")
+		v, err := strconv.ParseFloat(strings.TrimSuffix(field, "%"), 64)
+		if err != nil {
+			return color.RGBA{}, false
+		}
+		if percent {
+			v /= 100
+		}
+		if v < 0 || v > 1 {
+			return color.RGBA{}, false
+		}
+		alpha = v
+	}
+
+	return color.RGBA{
+		R: uint8(channels[0]*alpha + 0.5),
+		G: uint8(channels[1]*alpha + 0.5),
+		B: uint8(channels[2]*alpha + 0.5),
+		A: uint8(alpha*255 + 0.5),
+	}, true
+}
+
+// HexColor formats a color as #rrggbb, or #rrggbbaa when it is translucent
+func HexColor(c color.RGBA) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
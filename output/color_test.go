@@ -0,0 +1,108 @@
+package output
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+	}{
+		{"red", color.RGBA{205, 49, 49, 255}},
+		{" Bright-Blue ", color.RGBA{59, 142, 234, 255}},
+		{"grey", color.RGBA{102, 102, 102, 255}},
+		{"orange", color.RGBA{255, 140, 0, 255}},
+		{"transparent", color.RGBA{}},
+		{"#f80", color.RGBA{255, 136, 0, 255}},
+		{"#F80", color.RGBA{255, 136, 0, 255}},
+		{"#ff8800", color.RGBA{255, 136, 0, 255}},
+		{"#f008", color.RGBA{136, 0, 0, 136}},
+		{"#ff000080", color.RGBA{128, 0, 0, 128}},
+		{"#ffffff00", color.RGBA{}},
+		{"rgb(255, 136, 0)", color.RGBA{255, 136, 0, 255}},
+		{"rgba(0, 0, 255, 0.5)", color.RGBA{0, 0, 128, 128}},
+		{"RGBA(0,0,255,50%)", color.RGBA{0, 0, 128, 128}},
+		{"rgba(10, 20, 30, 0)", color.RGBA{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseColor(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseColorInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", "reddish", "bright-orange", "f80", "#12", "#12345", "#1234567", "#ggg",
+		"rgb(1, 2)", "rgb(256, 0, 0)", "rgb(-1, 0, 0)", "rgba(0, 0, 0, 1.5)",
+		"rgba(0, 0, 0, x)", "rgb(0, 0, 0", "rgb 0, 0, 0)",
+	} {
+		t.Run(in, func(t *testing.T) {
+			_, err := ParseColor(in)
+			if err == nil {
+				t.Fatalf("ParseColor(%q) succeeded, want error", in)
+			}
+			if want := "invalid color"; !strings.Contains(err.Error(), want) {
+				t.Errorf("error = %q, want it to contain %q", err, want)
+			}
+		})
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"#f80", "#ff8800"},
+		{"red", "#cd3131"},
+		{"#ff000080", "#ff000080"},
+		{"rgba(0, 0, 255, 0.5)", "#0000ff80"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := ParseColor(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := HexColor(c); got != tt.want {
+				t.Errorf("HexColor(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestANSIColorIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+		ok   bool
+	}{
+		{"black", 0, true},
+		{"White", 7, true},
+		{"bright-red", 9, true},
+		{"bright-white", 15, true},
+		{"orange", 0, false},
+		{"bright-", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ANSIColorIndex(tt.name)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ANSIColorIndex(%q) = %d, %v, want %d, %v", tt.name, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
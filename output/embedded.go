@@ -0,0 +1,45 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// EmbeddedFont is the font path of the monospace font built into the
+// program, Go Mono, which covers the box drawing characters of every table
+// style. It is the last fallback of every font, the default when no system
+// fonts are found and the only font of hermetic rendering.
+const EmbeddedFont = "embedded"
+
+//go:embed embedded/Go-Mono.ttf
+var embeddedFontData []byte
+
+var (
+	embeddedOnce sync.Once
+	embeddedFont *sfnt.Font
+	embeddedErr  error
+)
+
+// loadEmbeddedFont parses the embedded font once per run
+func loadEmbeddedFont() (*sfnt.Font, error) {
+	embeddedOnce.Do(func() {
+		embeddedFont, embeddedErr = opentype.Parse(embeddedFontData)
+		if embeddedErr != nil {
+			embeddedErr = fmt.Errorf("failed to parse embedded font: %v", embeddedErr)
+		}
+	})
+	return embeddedFont, embeddedErr
+}
+
+// embeddedFontInfo returns the metadata of the embedded font
+func embeddedFontInfo() []FontInfo {
+	infos, err := parseFontInfo(EmbeddedFont, embeddedFontData)
+	if err != nil {
+		return nil
+	}
+	return infos
+}
@@ -0,0 +1,146 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+)
+
+// fontChain is a font followed by its fallback fonts, in order
+type fontChain struct {
+	fonts []*sfnt.Font
+	paths []string // resolved path of each font
+	buf   sfnt.Buffer
+	found map[rune]int // cached results of index
+}
+
+// loadFontChain loads a font and its fallbacks. Fallbacks are resolved
+// like the main font path, by file path or system font name. The embedded
+// font ends every chain.
+func loadFontChain(path string, fallback []string) (*fontChain, error) {
+	chain := &fontChain{found: map[rune]int{}}
+	names := append([]string{path}, fallback...)
+	names = append(names, EmbeddedFont)
+	for i, name := range names {
+		if i > 0 && name == EmbeddedFont && slices.Contains(chain.paths, EmbeddedFont) {
+			continue
+		}
+		f, resolved, err := loadFont(name)
+		if err != nil {
+			if i > 0 {
+				return nil, fmt.Errorf("fallback: %v", err)
+			}
+			return nil, err
+		}
+		chain.fonts = append(chain.fonts, f)
+		chain.paths = append(chain.paths, resolved)
+	}
+	return chain, nil
+}
+
+// index returns the position of the first font with a glyph for r, or -1
+func (c *fontChain) index(r rune) int {
+	if i, ok := c.found[r]; ok {
+		return i
+	}
+	i := -1
+	for j, f := range c.fonts {
+		if hasGlyph(f, &c.buf, r) {
+			i = j
+			break
+		}
+	}
+	c.found[r] = i
+	return i
+}
+
+// coverage sorts the characters of the text, in order of appearance, by
+// the font of the chain that renders them, and lists those no font can
+// render. Spaces and control characters are not checked, nor are box
+// drawing characters when borders are drawn as strokes.
+func (c *fontChain) coverage(segments [][]TextSegment, strokes bool) (byFont [][]rune, missing []rune) {
+	byFont = make([][]rune, len(c.fonts))
+	seen := map[rune]bool{}
+	for _, line := range segments {
+		for _, seg := range line {
+			for _, r := range seg.Text {
+				if seen[r] || unicode.IsSpace(r) || unicode.IsControl(r) {
+					continue
+				}
+				seen[r] = true
+				if _, isBox := boxChars[r]; isBox && strokes {
+					continue
+				}
+				if i := c.index(r); i >= 0 {
+					byFont[i] = append(byFont[i], r)
+				} else {
+					missing = append(missing, r)
+				}
+			}
+		}
+	}
+	return byFont, missing
+}
+
+// missing lists the characters of the text no font of the chain can render
+func (c *fontChain) missing(segments [][]TextSegment, strokes bool) []rune {
+	_, missing := c.coverage(segments, strokes)
+	return missing
+}
+
+// faceChain holds the faces of a font chain at one size and resolution
+type faceChain struct {
+	chain *fontChain
+	faces []font.Face
+}
+
+// newFaceChain creates the faces of every font of a chain
+func newFaceChain(chain *fontChain, size, dpi float64) (*faceChain, error) {
+	fc := &faceChain{chain: chain}
+	for _, f := range chain.fonts {
+		face, err := newFace(f, size, dpi)
+		if err != nil {
+			return nil, err
+		}
+		fc.faces = append(fc.faces, face)
+	}
+	return fc, nil
+}
+
+// primary returns the face of the main font, which sets the layout metrics
+func (fc *faceChain) primary() font.Face {
+	return fc.faces[0]
+}
+
+// face returns the face to draw r with: the first font that has the glyph,
+// or the main font when none has it
+func (fc *faceChain) face(r rune) font.Face {
+	if i := fc.chain.index(r); i > 0 {
+		return fc.faces[i]
+	}
+	return fc.faces[0]
+}
+
+// FontCoverage reports which fonts of the ascii_font chain render the
+// characters of a table
+type FontCoverage struct {
+	Paths   []string // resolved fonts of the chain, main font first
+	Glyphs  [][]rune // characters rendered by each font of the chain
+	Missing []rune   // characters no font of the chain can render
+}
+
+// CheckFonts loads the configured fonts and reports how the ascii_font
+// chain, which draws the table, covers the characters of the table text
+func CheckFonts(tableText string, config PNGConfig) (*FontCoverage, error) {
+	chain, err := loadFonts(config)
+	if err != nil {
+		return nil, err
+	}
+	segments := parseTableText(tableText, nil)
+
+	glyphs, missing := chain.coverage(segments, config.spacing().strokes)
+	return &FontCoverage{Paths: chain.paths, Glyphs: glyphs, Missing: missing}, nil
+}
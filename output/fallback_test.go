@@ -0,0 +1,143 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeEmbeddedFont copies the embedded font to a file of a temporary
+// directory and returns its path
+func writeEmbeddedFont(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, embeddedFontData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFontChain(t *testing.T) {
+	path := writeEmbeddedFont(t, "Mono.ttf")
+
+	tests := []struct {
+		name     string
+		path     string
+		fallback []string
+		want     []string
+	}{
+		{"embedded", EmbeddedFont, nil, []string{EmbeddedFont}},
+		{"embedded listed again", EmbeddedFont, []string{EmbeddedFont}, []string{EmbeddedFont}},
+		{"file ends with the embedded font", path, nil, []string{path, EmbeddedFont}},
+		{"fallbacks in order", path, []string{EmbeddedFont, path}, []string{path, EmbeddedFont, path}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := loadFontChain(tt.path, tt.fallback)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(chain.paths, tt.want) {
+				t.Errorf("paths = %v, want %v", chain.paths, tt.want)
+			}
+			if len(chain.fonts) != len(tt.want) {
+				t.Errorf("loaded %d fonts, want %d", len(chain.fonts), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestLoadFontChainErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.ttf")
+	tests := []struct {
+		name     string
+		path     string
+		fallback []string
+		want     string
+	}{
+		{"main font", missing, nil, "failed to resolve font"},
+		{"fallback", EmbeddedFont, []string{missing}, "fallback: failed to resolve font"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadFontChain(tt.path, tt.fallback)
+			if err == nil {
+				t.Fatal("loadFontChain succeeded, want error")
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to start with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFontChainCoverage(t *testing.T) {
+	chain, err := loadFontChain(writeEmbeddedFont(t, "Mono.ttf"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments := parseTableText("┌──┐\n│ab 漢a│\n│\tü😀│\n└──┘", nil)
+
+	tests := []struct {
+		name        string
+		strokes     bool
+		wantByFont  []string
+		wantMissing string
+	}{
+		{"glyph borders", false, []string{"┌─┐│abü└┘", ""}, "漢😀"},
+		{"stroke borders", true, []string{"abü", ""}, "漢😀"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			byFont, missing := chain.coverage(segments, tt.strokes)
+			var got []string
+			for _, runes := range byFont {
+				got = append(got, string(runes))
+			}
+			if !reflect.DeepEqual(got, tt.wantByFont) {
+				t.Errorf("coverage by font = %q, want %q", got, tt.wantByFont)
+			}
+			if string(missing) != tt.wantMissing {
+				t.Errorf("coverage missing = %q, want %q", string(missing), tt.wantMissing)
+			}
+			if got := string(chain.missing(segments, tt.strokes)); got != tt.wantMissing {
+				t.Errorf("missing = %q, want %q", got, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestFontChainIndex(t *testing.T) {
+	chain, err := loadFontChain(EmbeddedFont, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		r    rune
+		want int
+	}{{'a', 0}, {'╬', 0}, {'漢', -1}} {
+		// The second lookup comes from the cache
+		for range 2 {
+			if got := chain.index(tt.r); got != tt.want {
+				t.Errorf("index(%q) = %d, want %d", tt.r, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCheckFontsHermetic(t *testing.T) {
+	config := PNGConfig{Hermetic: true, ASCIIFont: FontConfig{Path: "/no/such/font.ttf"}}
+	coverage, err := CheckFonts("│ café 漢 │", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &FontCoverage{Paths: []string{EmbeddedFont}, Glyphs: [][]rune{[]rune("│café")}, Missing: []rune("漢")}
+	if !reflect.DeepEqual(coverage, want) {
+		t.Errorf("CheckFonts = %+v, want %+v", coverage, want)
+	}
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fontCacheVersion changes whenever the cached font metadata changes shape
+const fontCacheVersion = 1
+
+// fontCache is the font index kept between runs, by font directory
+type fontCache struct {
+	Version int                    `json:"version"`
+	Dirs    map[string]*indexedDir `json:"dirs"`
+}
+
+// FontCachePath returns the file the font index is cached in, under the
+// user cache directory
+func FontCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no user cache directory: %v", err)
+	}
+	return filepath.Join(dir, "tablemaker", "fonts.json"), nil
+}
+
+// readFontCache reads the font cache. A missing, unreadable or outdated
+// cache reads as empty.
+func readFontCache(path string) *fontCache {
+	empty := &fontCache{Version: fontCacheVersion, Dirs: map[string]*indexedDir{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return empty
+	}
+	var cache fontCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Version != fontCacheVersion || cache.Dirs == nil {
+		return empty
+	}
+	return &cache
+}
+
+// write saves the font cache, replacing the file atomically
+func (c *fontCache) write(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode font cache: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create font cache directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "fonts-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write font cache: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write font cache: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write font cache: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write font cache: %v", err)
+	}
+	return nil
+}
+
+// stale reports whether any directory searched was changed, added to or
+// removed since it was indexed. Adding or removing a font changes the
+// modification time of its directory.
+func (d *indexedDir) stale() bool {
+	for dir, mtime := range d.MTimes {
+		info, err := os.Stat(dir)
+		if err != nil {
+			if mtime != 0 {
+				return true
+			}
+			continue
+		}
+		if !info.IsDir() || info.ModTime().UnixNano() != mtime {
+			return true
+		}
+	}
+	return false
+}
+
+// loadFontIndex builds the index of the font directories, reusing the
+// cached fonts of directories that did not change. With rebuild set every
+// directory is read again. The cache is rewritten when anything was read;
+// failing to write it is returned along with the index.
+func loadFontIndex(dirs []string, rebuild bool) (*FontIndex, error) {
+	path, pathErr := FontCachePath()
+	cache := &fontCache{Version: fontCacheVersion, Dirs: map[string]*indexedDir{}}
+	if pathErr == nil && !rebuild {
+		cache = readFontCache(path)
+	}
+
+	current := map[string]*indexedDir{}
+	indexed := make([]*indexedDir, 0, len(dirs))
+	changed := rebuild
+	for _, dir := range dirs {
+		dir = absDir(dir)
+		entry, ok := current[dir]
+		if !ok {
+			entry, ok = cache.Dirs[dir]
+			if !ok || entry.stale() {
+				entry = indexDir(dir)
+				changed = true
+			}
+			current[dir] = entry
+		}
+		indexed = append(indexed, entry)
+	}
+	// Directories of other runs (such as another ./fonts) are dropped
+	if len(current) != len(cache.Dirs) {
+		changed = true
+	}
+
+	idx := mergeIndex(indexed)
+	if pathErr != nil {
+		return idx, pathErr
+	}
+	if changed {
+		cache.Dirs = current
+		return idx, cache.write(path)
+	}
+	return idx, nil
+}
+
+// RebuildFontIndex reads every font directory again and replaces the font
+// cache, returning the new index and the cache path
+func RebuildFontIndex() (*FontIndex, string, error) {
+	path, err := FontCachePath()
+	if err != nil {
+		return nil, "", err
+	}
+	idx, err := loadFontIndex(getFontDirectories(), true)
+	return idx, path, err
+}
@@ -0,0 +1,251 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useCacheDir points the user cache directory, and so the font cache, at a
+// temporary directory
+func useCacheDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LocalAppData", dir)
+	path, err := FontCachePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// touch moves the modification time of a path forward, as adding or
+// removing a file does
+func touch(t *testing.T, path string) {
+	t.Helper()
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// indexPaths returns the font paths of an index relative to dir
+func indexPaths(t *testing.T, idx *FontIndex, dir string) []string {
+	t.Helper()
+	var paths []string
+	for _, fi := range idx.Fonts {
+		rel, err := filepath.Rel(dir, fi.Spec())
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+func TestFontCacheReuse(t *testing.T) {
+	cachePath := useCacheDir(t)
+	dir := writeFontFiles(t, map[string][]byte{"Mono.ttf": embeddedFontData})
+
+	if _, err := loadFontIndex([]string{dir}, false); err != nil {
+		t.Fatal(err)
+	}
+	cache := readFontCache(cachePath)
+	entry, ok := cache.Dirs[dir]
+	if !ok {
+		t.Fatalf("cache has no entry for %s: %v", dir, cache.Dirs)
+	}
+
+	// An unchanged directory is read from the cache, not from disk
+	entry.Fonts = append(entry.Fonts, FontInfo{Path: filepath.Join(dir, "Cached.ttf")})
+	if err := cache.write(cachePath); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := loadFontIndex([]string{dir}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexPaths(t, idx, dir), []string{"Mono.ttf", "Cached.ttf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cached index = %v, want %v", got, want)
+	}
+
+	// Rebuilding reads the directory again
+	idx, err = loadFontIndex([]string{dir}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexPaths(t, idx, dir), []string{"Mono.ttf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rebuilt index = %v, want %v", got, want)
+	}
+}
+
+func TestFontCacheStale(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, dir string)
+		want   []string
+	}{
+		{
+			name: "font added",
+			change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "Added.ttf"), embeddedFontData)
+				touch(t, dir)
+			},
+			want: []string{"Added.ttf", "Mono.ttf", "sub/CJK.ttc", "sub/CJK.ttc#1"},
+		},
+		{
+			name: "subdirectory changed",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "sub", "CJK.ttc")); err != nil {
+					t.Fatal(err)
+				}
+				touch(t, filepath.Join(dir, "sub"))
+			},
+			want: []string{"Mono.ttf"},
+		},
+		{
+			name: "subdirectory removed",
+			change: func(t *testing.T, dir string) {
+				if err := os.RemoveAll(filepath.Join(dir, "sub")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"Mono.ttf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCacheDir(t)
+			dir := writeFontFiles(t, map[string][]byte{
+				"Mono.ttf":    embeddedFontData,
+				"sub/CJK.ttc": collectionData(embeddedFontData, 2),
+			})
+			if _, err := loadFontIndex([]string{dir}, false); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.change(t, dir)
+			idx, err := loadFontIndex([]string{dir}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := indexPaths(t, idx, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("index = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFontCacheRelativeDir(t *testing.T) {
+	cachePath := useCacheDir(t)
+	first := writeFontFiles(t, map[string][]byte{"fonts/First.ttf": embeddedFontData})
+	second := writeFontFiles(t, map[string][]byte{"fonts/Second.ttf": embeddedFontData})
+
+	// The same relative directory names a different directory, and cache
+	// entry, in each working directory
+	for _, tt := range []struct{ dir, font string }{{first, "First.ttf"}, {second, "Second.ttf"}, {first, "First.ttf"}} {
+		t.Chdir(tt.dir)
+		idx, err := loadFontIndex([]string{"./fonts"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fonts := filepath.Join(tt.dir, "fonts")
+		if got, want := indexPaths(t, idx, fonts), []string{tt.font}; !reflect.DeepEqual(got, want) {
+			t.Errorf("index in %s = %v, want %v", tt.dir, got, want)
+		}
+		if _, ok := readFontCache(cachePath).Dirs[fonts]; !ok {
+			t.Errorf("cache has no entry for %s", fonts)
+		}
+	}
+}
+
+func TestIndexedDirStale(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Mono.ttf")
+	writeFile(t, file, embeddedFontData)
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtime := info.ModTime().UnixNano()
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name   string
+		mtimes map[string]int64
+		want   bool
+	}{
+		{"unchanged", map[string]int64{dir: mtime}, false},
+		{"modified", map[string]int64{dir: mtime - 1}, true},
+		{"still missing", map[string]int64{missing: 0}, false},
+		{"removed", map[string]int64{missing: mtime}, true},
+		{"created", map[string]int64{dir: 0}, true},
+		{"replaced by a file", map[string]int64{file: mtime}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&indexedDir{MTimes: tt.mtimes}).stale(); got != tt.want {
+				t.Errorf("stale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFontCache(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		data string
+		dirs int
+	}{
+		{"valid", `{"version": 1, "dirs": {"/fonts": {"mtimes": {"/fonts": 1}, "fonts": []}}}`, 1},
+		{"other version", `{"version": 0, "dirs": {"/fonts": {}}}`, 0},
+		{"no directories", `{"version": 1}`, 0},
+		{"invalid", `{"version": 1, "dirs": [`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			writeFile(t, path, []byte(tt.data))
+			cache := readFontCache(path)
+			if cache.Version != fontCacheVersion || cache.Dirs == nil || len(cache.Dirs) != tt.dirs {
+				t.Errorf("readFontCache = %+v, want version %d and %d directories", cache, fontCacheVersion, tt.dirs)
+			}
+		})
+	}
+
+	if cache := readFontCache(filepath.Join(dir, "missing.json")); cache.Dirs == nil || len(cache.Dirs) != 0 {
+		t.Errorf("readFontCache of a missing file = %+v", cache)
+	}
+}
+
+func TestGetFontDirectoriesAbsolute(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dirs := getFontDirectories()
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			t.Errorf("font directory %q is relative", dir)
+		}
+		if seen[dir] {
+			t.Errorf("font directory %q listed twice", dir)
+		}
+		seen[dir] = true
+	}
+	if wd, _ := os.Getwd(); len(dirs) < 2 || dirs[0] != filepath.Join(wd, "fonts") || dirs[1] != wd {
+		t.Errorf("font directories start with %v, want ./fonts and . of %s", dirs[:min(len(dirs), 2)], wd)
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
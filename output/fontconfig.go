@@ -0,0 +1,338 @@
+package output
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fontconfig holds what tablemaker uses of a fontconfig configuration: the
+// font directories and the family substitutes of alias rules
+type fontconfig struct {
+	dirs []string
+	// Substitutes by normalized family, as in <alias> prefer, accept and
+	// default lists
+	prefer, accept, fallback map[string][]string
+}
+
+// fcElement is an element of a fontconfig file. Only <dir>, <include>,
+// <alias> and simple family <match> rules are used.
+type fcElement struct {
+	XMLName       xml.Name
+	Prefix        string   `xml:"prefix,attr"`
+	IgnoreMissing string   `xml:"ignore_missing,attr"`
+	Target        string   `xml:"target,attr"`
+	Text          string   `xml:",chardata"`
+	Families      []string `xml:"family"`
+	Prefer        []string `xml:"prefer>family"`
+	Accept        []string `xml:"accept>family"`
+	Default       []string `xml:"default>family"`
+	Tests         []fcTest `xml:"test"`
+	Edits         []fcEdit `xml:"edit"`
+}
+
+// fcTest is a test of a <match> rule
+type fcTest struct {
+	Name    string   `xml:"name,attr"`
+	Compare string   `xml:"compare,attr"`
+	Strings []string `xml:"string"`
+}
+
+// fcEdit is an edit of a <match> rule
+type fcEdit struct {
+	Name    string   `xml:"name,attr"`
+	Mode    string   `xml:"mode,attr"`
+	Strings []string `xml:"string"`
+}
+
+// fcDocument is a fontconfig file with its elements in document order
+type fcDocument struct {
+	Elements []fcElement `xml:",any"`
+}
+
+// maxIncludeDepth bounds nested <include> elements
+const maxIncludeDepth = 16
+
+// fontconfigFile returns the main fontconfig file: FONTCONFIG_FILE when set
+// (relative to FONTCONFIG_PATH or /etc/fonts), otherwise
+// /etc/fonts/fonts.conf
+func fontconfigFile() string {
+	dir := os.Getenv("FONTCONFIG_PATH")
+	if dir == "" {
+		dir = "/etc/fonts"
+	}
+	file := os.Getenv("FONTCONFIG_FILE")
+	if file == "" {
+		return filepath.Join(dir, "fonts.conf")
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	return file
+}
+
+// loadFontconfig reads a fontconfig file and the files it includes. It
+// returns nil when the file cannot be read.
+func loadFontconfig(path string) *fontconfig {
+	fc := &fontconfig{
+		prefer:   map[string][]string{},
+		accept:   map[string][]string{},
+		fallback: map[string][]string{},
+	}
+	if !fc.parseFile(path, map[string]bool{}, 0) {
+		return nil
+	}
+	return fc
+}
+
+// parseFile reads one fontconfig file, reporting whether it could be read.
+// Malformed files are skipped like fontconfig skips them.
+func (fc *fontconfig) parseFile(path string, seen map[string]bool, depth int) bool {
+	if seen[path] || depth > maxIncludeDepth {
+		return true
+	}
+	seen[path] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var doc fcDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return true
+	}
+
+	configDir := filepath.Dir(path)
+	for _, el := range doc.Elements {
+		switch el.XMLName.Local {
+		case "dir":
+			if dir := fcPath(el.Text, el.Prefix, configDir, false); dir != "" {
+				fc.dirs = append(fc.dirs, dir)
+			}
+		case "include":
+			if include := fcPath(el.Text, el.Prefix, configDir, true); include != "" {
+				fc.include(include, seen, depth+1)
+			}
+		case "alias":
+			for _, family := range el.Families {
+				fc.addAlias(family, el.Prefer, el.Accept, el.Default)
+			}
+		case "match":
+			fc.addMatch(el)
+		}
+	}
+	return true
+}
+
+// include reads an included file, or the .conf files of an included
+// directory in name order
+func (fc *fontconfig) include(path string, seen map[string]bool, depth int) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if !info.IsDir() {
+		fc.parseFile(path, seen, depth)
+		return
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".conf") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fc.parseFile(filepath.Join(path, name), seen, depth)
+	}
+}
+
+// addAlias records the substitutes of an <alias> family
+func (fc *fontconfig) addAlias(family string, prefer, accept, fallback []string) {
+	key := normalizeFontName(family)
+	if key == "" {
+		return
+	}
+	trim := func(families []string) []string {
+		var trimmed []string
+		for _, f := range families {
+			if f = strings.TrimSpace(f); f != "" {
+				trimmed = append(trimmed, f)
+			}
+		}
+		return trimmed
+	}
+	fc.prefer[key] = append(fc.prefer[key], trim(prefer)...)
+	fc.accept[key] = append(fc.accept[key], trim(accept)...)
+	fc.fallback[key] = append(fc.fallback[key], trim(fallback)...)
+}
+
+// addMatch records <match> rules that test for one family name and edit
+// the family, such as the rule replacing "mono" with "monospace". Other
+// rules are ignored.
+func (fc *fontconfig) addMatch(el fcElement) {
+	if el.Target != "" && el.Target != "pattern" {
+		return
+	}
+	if len(el.Tests) != 1 || el.Tests[0].Name != "family" || len(el.Tests[0].Strings) != 1 {
+		return
+	}
+	if compare := el.Tests[0].Compare; compare != "" && compare != "eq" {
+		return
+	}
+
+	family := el.Tests[0].Strings[0]
+	for _, edit := range el.Edits {
+		if edit.Name != "family" {
+			continue
+		}
+		switch edit.Mode {
+		case "", "assign", "assign_replace", "prepend", "prepend_first":
+			fc.addAlias(family, edit.Strings, nil, nil)
+		case "append":
+			fc.addAlias(family, nil, edit.Strings, nil)
+		case "append_last":
+			fc.addAlias(family, nil, nil, edit.Strings)
+		}
+	}
+}
+
+// fcPath resolves the path of a <dir> or <include> element. "~" is the home
+// directory; prefix "xdg" is relative to XDG_DATA_HOME for directories and
+// XDG_CONFIG_HOME for includes, and prefix "relative" to the directory of
+// the configuration file. Other relative includes are relative to the
+// configuration directory and other relative directories to the current
+// directory.
+func fcPath(text, prefix, configDir string, include bool) string {
+	path := strings.TrimSpace(text)
+	if path == "" {
+		return ""
+	}
+
+	if rest, ok := strings.CutPrefix(path, "~"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(home, rest)
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	switch {
+	case prefix == "xdg" && include:
+		return filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), path)
+	case prefix == "xdg":
+		return filepath.Join(xdgDir("XDG_DATA_HOME", ".local/share"), path)
+	case prefix == "relative" || include:
+		return filepath.Join(configDir, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// xdgDir returns an XDG base directory from its environment variable, or
+// its default under the home directory
+func xdgDir(env, def string) string {
+	if dir := os.Getenv(env); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, def)
+}
+
+// xdgFontDirs returns the fonts directories of XDG_DATA_HOME and
+// XDG_DATA_DIRS
+func xdgFontDirs() []string {
+	dirs := []string{filepath.Join(xdgDir("XDG_DATA_HOME", ".local/share"), "fonts")}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "fonts"))
+		}
+	}
+	return dirs
+}
+
+// builtinAliases are the generic families used when fontconfig has no
+// preference for them
+var builtinAliases = map[string][]string{
+	"monospace": SystemMonospace.Names,
+	"sansserif": SystemSansSerif.Names,
+	"serif":     {"DejaVu Serif", "Times New Roman", "Liberation Serif", "FreeSerif", "Noto Serif", "Times"},
+	"mono":      {"monospace"},
+	"sans":      {"sans-serif"},
+}
+
+var (
+	systemFontconfigOnce sync.Once
+	systemFontconfigData *fontconfig
+)
+
+// systemFontconfig returns the fontconfig configuration of the system, or
+// nil on Windows, macOS and systems without one
+func systemFontconfig() *fontconfig {
+	systemFontconfigOnce.Do(func() {
+		if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+			return
+		}
+		systemFontconfigData = loadFontconfig(fontconfigFile())
+	})
+	return systemFontconfigData
+}
+
+// fontSubstitutes returns the families to try for a font name that is not
+// installed, by the system fontconfig configuration
+func fontSubstitutes(name string) []string {
+	return systemFontconfig().substitutes(name)
+}
+
+// substitutes returns the families to try for a font name, in order: its
+// fontconfig aliases, followed recursively by theirs, then the built-in
+// preferences of generic families such as "monospace", "sans-serif" and
+// "serif". A nil configuration has only the built-in preferences.
+func (fc *fontconfig) substitutes(name string) []string {
+	var substitutes []string
+	seen := map[string]bool{normalizeFontName(name): true}
+
+	var expand func(family string, depth int)
+	expand = func(family string, depth int) {
+		if depth > maxIncludeDepth {
+			return
+		}
+		key := normalizeFontName(family)
+		var lists [][]string
+		if fc != nil {
+			lists = append(lists, fc.prefer[key], fc.accept[key], fc.fallback[key])
+		}
+		lists = append(lists, builtinAliases[key])
+
+		for _, list := range lists {
+			for _, substitute := range list {
+				if seen[normalizeFontName(substitute)] {
+					continue
+				}
+				seen[normalizeFontName(substitute)] = true
+				substitutes = append(substitutes, substitute)
+				expand(substitute, depth+1)
+			}
+		}
+	}
+	expand(name, 0)
+	return substitutes
+}
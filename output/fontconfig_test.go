@@ -0,0 +1,236 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testFontconfig is a fontconfig file exercising the elements tablemaker reads
+const testFontconfig = `<?xml version="1.0"?>
+<!DOCTYPE fontconfig SYSTEM "urn:fontconfig:fonts.dtd">
+<fontconfig>
+  <dir>/opt/fonts</dir>
+  <dir prefix="relative">local</dir>
+  <dir prefix="xdg">fonts</dir>
+  <dir>~/.fonts</dir>
+  <dir>  </dir>
+  <include ignore_missing="yes">conf.d</include>
+  <include ignore_missing="yes">missing.conf</include>
+  <include>fonts.conf</include>
+  <alias>
+    <family>Helvetica</family>
+    <family>Helvetica Neue</family>
+    <prefer><family>Arial</family></prefer>
+    <accept><family> Liberation Sans </family></accept>
+    <default><family>sans-serif</family></default>
+  </alias>
+  <match target="pattern">
+    <test qual="any" name="family"><string>mono</string></test>
+    <edit name="family" mode="assign" binding="same"><string>monospace</string></edit>
+  </match>
+  <match>
+    <test name="family"><string>Courier</string></test>
+    <edit name="family" mode="append"><string>Courier New</string></edit>
+    <edit name="family" mode="append_last"><string>monospace</string></edit>
+    <edit name="weight" mode="assign"><const>bold</const></edit>
+  </match>
+  <match target="font">
+    <test name="family"><string>Ignored Target</string></test>
+    <edit name="family"><string>Arial</string></edit>
+  </match>
+  <match>
+    <test name="family" compare="contains"><string>Ignored Compare</string></test>
+    <edit name="family"><string>Arial</string></edit>
+  </match>
+  <match>
+    <test name="lang"><string>ja</string></test>
+    <edit name="family"><string>Noto Sans CJK JP</string></edit>
+  </match>
+</fontconfig>
+`
+
+func TestLoadFontconfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "/xdg/data")
+
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"fonts.conf":            testFontconfig,
+		"conf.d/20-second.conf": `<fontconfig><alias><family>Sans</family><prefer><family>B</family></prefer></alias></fontconfig>`,
+		"conf.d/10-first.conf":  `<fontconfig><alias><family>sans</family><prefer><family>A</family></prefer></alias><include>../extra/dirs.conf</include></fontconfig>`,
+		"conf.d/15-broken.conf": `<fontconfig><alias><family>Sans</family>`,
+		"conf.d/30-skipped.txt": `<fontconfig><dir>/skipped</dir></fontconfig>`,
+		"extra/dirs.conf":       `<fontconfig><dir prefix="relative">../shared</dir><include>../fonts.conf</include></fontconfig>`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, []byte(data))
+	}
+	// Directories named like files are not read
+	if err := os.Mkdir(filepath.Join(dir, "conf.d", "40-nested.conf"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	fc := loadFontconfig(filepath.Join(dir, "fonts.conf"))
+	if fc == nil {
+		t.Fatal("loadFontconfig returned nil")
+	}
+
+	wantDirs := []string{
+		"/opt/fonts",
+		filepath.Join(dir, "local"),
+		"/xdg/data/fonts",
+		filepath.Join(home, ".fonts"),
+		filepath.Join(dir, "shared"),
+	}
+	if !reflect.DeepEqual(fc.dirs, wantDirs) {
+		t.Errorf("dirs = %q, want %q", fc.dirs, wantDirs)
+	}
+
+	tests := []struct {
+		family                   string
+		prefer, accept, fallback []string
+	}{
+		{"Helvetica", []string{"Arial"}, []string{"Liberation Sans"}, []string{"sans-serif"}},
+		{"helvetica-neue", []string{"Arial"}, []string{"Liberation Sans"}, []string{"sans-serif"}},
+		{"Sans", []string{"A", "B"}, nil, nil},
+		{"mono", []string{"monospace"}, nil, nil},
+		{"Courier", nil, []string{"Courier New"}, []string{"monospace"}},
+		{"Ignored Target", nil, nil, nil},
+		{"Ignored Compare", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.family, func(t *testing.T) {
+			key := normalizeFontName(tt.family)
+			got := [][]string{fc.prefer[key], fc.accept[key], fc.fallback[key]}
+			want := [][]string{tt.prefer, tt.accept, tt.fallback}
+			for i, list := range []string{"prefer", "accept", "default"} {
+				if len(got[i]) != 0 || len(want[i]) != 0 {
+					if !reflect.DeepEqual(got[i], want[i]) {
+						t.Errorf("%s = %q, want %q", list, got[i], want[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFontconfigMissing(t *testing.T) {
+	if fc := loadFontconfig(filepath.Join(t.TempDir(), "fonts.conf")); fc != nil {
+		t.Errorf("loadFontconfig of a missing file = %+v, want nil", fc)
+	}
+}
+
+func TestFontconfigSubstitutes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fonts.conf")
+	writeFile(t, path, []byte(testFontconfig))
+	fc := loadFontconfig(path)
+	if fc == nil {
+		t.Fatal("loadFontconfig returned nil")
+	}
+
+	tests := []struct {
+		name string
+		fc   *fontconfig
+		want []string
+	}{
+		{
+			name: "Helvetica",
+			fc:   fc,
+			want: []string{"Arial", "Liberation Sans", "sans-serif", "DejaVu Sans", "FreeSans", "Noto Sans", "Source Code Pro"},
+		},
+		{
+			name: "mono",
+			fc:   fc,
+			want: []string{"monospace", "DejaVu Sans Mono", "Courier New", "Liberation Mono", "FreeMono", "Noto Sans Mono", "Consolas", "Source Code Pro"},
+		},
+		{
+			name: "Courier",
+			fc:   fc,
+			want: []string{"Courier New", "monospace", "DejaVu Sans Mono", "Liberation Mono", "FreeMono", "Noto Sans Mono", "Consolas", "Source Code Pro"},
+		},
+		{
+			name: "sans",
+			want: []string{"sans-serif", "DejaVu Sans", "Arial", "Helvetica", "Liberation Sans", "FreeSans", "Noto Sans", "Source Code Pro"},
+		},
+		{
+			name: "Arial",
+			fc:   fc,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fc.substitutes(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("substitutes(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFcPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "/xdg/data")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text, prefix string
+		include      bool
+		want         string
+	}{
+		{"/usr/share/fonts", "", false, "/usr/share/fonts"},
+		{" /usr/share/fonts ", "xdg", false, "/usr/share/fonts"},
+		{"~/.fonts", "", false, filepath.Join(home, ".fonts")},
+		{"fonts", "xdg", false, "/xdg/data/fonts"},
+		{"fontconfig/conf.d", "xdg", true, "/xdg/config/fontconfig/conf.d"},
+		{"local", "relative", false, "/etc/fonts/local"},
+		{"conf.d", "", true, "/etc/fonts/conf.d"},
+		{"fonts", "", false, filepath.Join(wd, "fonts")},
+		{"", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := fcPath(tt.text, tt.prefix, "/etc/fonts", tt.include); got != tt.want {
+			t.Errorf("fcPath(%q, %q, include %v) = %q, want %q", tt.text, tt.prefix, tt.include, got, tt.want)
+		}
+	}
+}
+
+func TestFontconfigFile(t *testing.T) {
+	tests := []struct {
+		path, file string
+		want       string
+	}{
+		{"", "", "/etc/fonts/fonts.conf"},
+		{"/opt/fc", "", "/opt/fc/fonts.conf"},
+		{"", "local.conf", "/etc/fonts/local.conf"},
+		{"/opt/fc", "local.conf", "/opt/fc/local.conf"},
+		{"/opt/fc", "/home/me/fonts.conf", "/home/me/fonts.conf"},
+	}
+	for _, tt := range tests {
+		t.Setenv("FONTCONFIG_PATH", tt.path)
+		t.Setenv("FONTCONFIG_FILE", tt.file)
+		if got := fontconfigFile(); got != tt.want {
+			t.Errorf("fontconfigFile() with FONTCONFIG_PATH=%q FONTCONFIG_FILE=%q = %q, want %q", tt.path, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestXDGFontDirs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/xdg/data")
+	t.Setenv("XDG_DATA_DIRS", "/usr/share::/opt/share")
+	want := []string{"/xdg/data/fonts", "/usr/share/fonts", "/opt/share/fonts"}
+	if got := xdgFontDirs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("xdgFontDirs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// fontExtensions are the font file types that can be loaded: TrueType and
+// OpenType (CFF) fonts and collections of either
+var fontExtensions = []string{".ttf", ".otf", ".ttc", ".otc"}
+
+// isFontFile reports whether a file name has a loadable font extension
+func isFontFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range fontExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFaceIndex splits a "#N" face index suffix from a font path, as in
+// "NotoSansCJK-Regular.ttc#2". Paths without a suffix select face 0.
+func splitFaceIndex(fontPath string) (string, int) {
+	i := strings.LastIndexByte(fontPath, '#')
+	if i < 0 {
+		return fontPath, 0
+	}
+	index, err := strconv.Atoi(fontPath[i+1:])
+	if err != nil || index < 0 {
+		return fontPath, 0
+	}
+	return fontPath[:i], index
+}
+
+// parseFontFile parses a font file. Collections (.ttc, .otc) return the
+// face at index; single fonts only have face 0.
+func parseFontFile(path string, index int) (*sfnt.Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font file '%s': %v", path, err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("ttcf")) {
+		if index != 0 {
+			return nil, fmt.Errorf("font file '%s' is not a collection, face index %d is out of range", path, index)
+		}
+		f, err := opentype.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse font file '%s': %v", path, err)
+		}
+		return f, nil
+	}
+
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font collection '%s': %v", path, err)
+	}
+	if index >= collection.NumFonts() {
+		return nil, fmt.Errorf("font collection '%s' has %d faces, face index %d is out of range", path, collection.NumFonts(), index)
+	}
+	f, err := collection.Font(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse face %d of font collection '%s': %v", index, path, err)
+	}
+	return f, nil
+}
+
+// hasGlyph reports whether a font maps r to a glyph
+func hasGlyph(f *sfnt.Font, buf *sfnt.Buffer, r rune) bool {
+	index, err := f.GlyphIndex(buf, r)
+	return err == nil && index != 0
+}
+
+// newFace creates a face of a font at a size in points and a resolution
+func newFace(f *sfnt.Font, size, dpi float64) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: dpi, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %v", err)
+	}
+	return face, nil
+}
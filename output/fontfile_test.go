@@ -0,0 +1,140 @@
+package output
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// collectionData wraps a single font as a collection of n identical faces
+func collectionData(font []byte, n int) []byte {
+	header := 12 + 4*n
+	data := make([]byte, header, header+len(font))
+	copy(data, "ttcf")
+	binary.BigEndian.PutUint32(data[4:], 0x00010000)
+	binary.BigEndian.PutUint32(data[8:], uint32(n))
+	for i := 0; i < n; i++ {
+		binary.BigEndian.PutUint32(data[12+4*i:], uint32(header))
+	}
+	data = append(data, font...)
+
+	// Table offsets count from the start of the file
+	tables := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < tables; i++ {
+		at := header + 12 + 16*i + 8
+		binary.BigEndian.PutUint32(data[at:], binary.BigEndian.Uint32(data[at:])+uint32(header))
+	}
+	return data
+}
+
+// writeFontFiles writes files to a temporary directory and returns it
+func writeFontFiles(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestSplitFaceIndex(t *testing.T) {
+	tests := []struct {
+		in    string
+		path  string
+		index int
+	}{
+		{"NotoSansCJK-Regular.ttc", "NotoSansCJK-Regular.ttc", 0},
+		{"NotoSansCJK-Regular.ttc#2", "NotoSansCJK-Regular.ttc", 2},
+		{"fonts/Mono.ttc#0", "fonts/Mono.ttc", 0},
+		{"fonts#1/Mono.ttc#10", "fonts#1/Mono.ttc", 10},
+		{"fonts#1/Mono.ttf", "fonts#1/Mono.ttf", 0},
+		{"Mono.ttc#", "Mono.ttc#", 0},
+		{"Mono.ttc#-1", "Mono.ttc#-1", 0},
+		{"Mono.ttc#one", "Mono.ttc#one", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			path, index := splitFaceIndex(tt.in)
+			if path != tt.path || index != tt.index {
+				t.Errorf("splitFaceIndex(%q) = %q, %d, want %q, %d", tt.in, path, index, tt.path, tt.index)
+			}
+		})
+	}
+}
+
+func TestIsFontFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Mono.ttf": true, "Mono.OTF": true, "CJK.ttc": true, "CJK.otc": true,
+		"Mono.woff": false, "fonts.conf": false, "ttf": false,
+	} {
+		if got := isFontFile(name); got != want {
+			t.Errorf("isFontFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseFontFile(t *testing.T) {
+	dir := writeFontFiles(t, map[string][]byte{
+		"Mono.ttf":  embeddedFontData,
+		"Mono.ttc":  collectionData(embeddedFontData, 2),
+		"Empty.ttf": []byte("not a font"),
+		"Empty.ttc": []byte("ttcf"),
+	})
+
+	tests := []struct {
+		file    string
+		index   int
+		wantErr string
+	}{
+		{"Mono.ttf", 0, ""},
+		{"Mono.ttc", 0, ""},
+		{"Mono.ttc", 1, ""},
+		{"Mono.ttf", 1, "is not a collection, face index 1 is out of range"},
+		{"Mono.ttc", 2, "has 2 faces, face index 2 is out of range"},
+		{"Empty.ttf", 0, "failed to parse font file"},
+		{"Empty.ttc", 0, "failed to parse font collection"},
+		{"Missing.ttf", 0, "failed to read font file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s#%d", tt.file, tt.index), func(t *testing.T) {
+			f, err := parseFontFile(filepath.Join(dir, tt.file), tt.index)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseFontFile error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.NumGlyphs() == 0 {
+				t.Error("parsed font has no glyphs")
+			}
+		})
+	}
+}
+
+func TestLoadFontFaceIndex(t *testing.T) {
+	dir := writeFontFiles(t, map[string][]byte{"Mono.ttc": collectionData(embeddedFontData, 2)})
+	path := filepath.Join(dir, "Mono.ttc")
+
+	for _, spec := range []string{path, path + "#1"} {
+		if _, resolved, err := loadFont(spec); err != nil || resolved != spec {
+			t.Errorf("loadFont(%q) = %q, %v", spec, resolved, err)
+		}
+	}
+	if _, _, err := loadFont(path + "#5"); err == nil {
+		t.Errorf("loadFont(%q) succeeded, want error", path+"#5")
+	}
+}
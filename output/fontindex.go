@@ -0,0 +1,445 @@
+package output
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontInfo describes one face of a font file, read from its name, OS/2 and
+// post tables
+type FontInfo struct {
+	Path           string `json:"path"`
+	Index          int    `json:"index,omitempty"` // face within a collection
+	Family         string `json:"family"`
+	Style          string `json:"style"`
+	FullName       string `json:"full_name,omitempty"`
+	PostScriptName string `json:"postscript_name,omitempty"`
+	// Legacy family and style names, when they differ from the typographic
+	// ones (e.g. "Arial Black" / "Regular" for "Arial" / "Black")
+	LegacyFamily string `json:"legacy_family,omitempty"`
+	LegacyStyle  string `json:"legacy_style,omitempty"`
+	Weight       int    `json:"weight"`
+	Monospace    bool   `json:"monospace"`
+}
+
+// Spec returns the path that loads the face: the file path, with a #N
+// suffix for later faces of a collection
+func (fi FontInfo) Spec() string {
+	if fi.Index == 0 {
+		return fi.Path
+	}
+	return fi.Path + "#" + strconv.Itoa(fi.Index)
+}
+
+// Name returns the display name of the face
+func (fi FontInfo) Name() string {
+	if fi.FullName != "" {
+		return fi.FullName
+	}
+	return strings.TrimSpace(fi.Family + " " + fi.Style)
+}
+
+// readFontInfo reads the metadata of every face of a font file
+func readFontInfo(path string) ([]FontInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseFontInfo(path, data)
+}
+
+// parseFontInfo reads the metadata of every face of font data loaded from
+// path
+func parseFontInfo(path string, data []byte) ([]FontInfo, error) {
+	// Table directory offsets of the faces
+	offsets := []int{0}
+	var faces []*sfnt.Font
+	if strings.HasPrefix(string(data[:min(len(data), 4)]), "ttcf") {
+		collection, err := opentype.ParseCollection(data)
+		if err != nil {
+			return nil, err
+		}
+		offsets = offsets[:0]
+		for i := 0; i < collection.NumFonts(); i++ {
+			f, err := collection.Font(i)
+			if err != nil {
+				return nil, err
+			}
+			faces = append(faces, f)
+			at := 12 + 4*i
+			if at+4 <= len(data) {
+				offsets = append(offsets, int(binary.BigEndian.Uint32(data[at:])))
+			} else {
+				offsets = append(offsets, -1)
+			}
+		}
+	} else {
+		f, err := opentype.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		faces = append(faces, f)
+	}
+
+	var buf sfnt.Buffer
+	infos := make([]FontInfo, 0, len(faces))
+	for i, f := range faces {
+		name := func(ids ...sfnt.NameID) string {
+			for _, id := range ids {
+				if s, err := f.Name(&buf, id); err == nil && strings.TrimSpace(s) != "" {
+					return strings.TrimSpace(s)
+				}
+			}
+			return ""
+		}
+
+		info := FontInfo{
+			Path:           path,
+			Index:          i,
+			Family:         name(sfnt.NameIDTypographicFamily, sfnt.NameIDFamily),
+			Style:          name(sfnt.NameIDTypographicSubfamily, sfnt.NameIDSubfamily),
+			FullName:       name(sfnt.NameIDFull),
+			PostScriptName: name(sfnt.NameIDPostScript),
+			Weight:         os2Weight(data, offsets[i]),
+			Monospace:      isMonospace(f, &buf),
+		}
+		if family := name(sfnt.NameIDFamily); family != info.Family {
+			info.LegacyFamily = family
+			info.LegacyStyle = name(sfnt.NameIDSubfamily)
+		}
+		if info.Weight == 0 {
+			info.Weight = 400
+			if strings.Contains(strings.ToLower(info.Style), "bold") {
+				info.Weight = 700
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// os2Weight reads the weight class from the OS/2 table of the face whose
+// table directory starts at offset, or returns 0 when there is none
+func os2Weight(data []byte, offset int) int {
+	if offset < 0 || offset+12 > len(data) {
+		return 0
+	}
+	tables := int(binary.BigEndian.Uint16(data[offset+4:]))
+	for i := 0; i < tables; i++ {
+		record := offset + 12 + 16*i
+		if record+16 > len(data) {
+			return 0
+		}
+		if string(data[record:record+4]) != "OS/2" {
+			continue
+		}
+		at := int(binary.BigEndian.Uint32(data[record+8:]))
+		if at+6 > len(data) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(data[at+4:]))
+	}
+	return 0
+}
+
+// isMonospace reports whether a font is fixed pitch, by its post table or
+// by narrow and wide letters having the same advance
+func isMonospace(f *sfnt.Font, buf *sfnt.Buffer) bool {
+	if post := f.PostTable(); post != nil && post.IsFixedPitch {
+		return true
+	}
+
+	ppem := fixed.Int26_6(f.UnitsPerEm())
+	var advances []fixed.Int26_6
+	for _, r := range "iM" {
+		index, err := f.GlyphIndex(buf, r)
+		if err != nil || index == 0 {
+			return false
+		}
+		advance, err := f.GlyphAdvance(buf, index, ppem, font.HintingNone)
+		if err != nil {
+			return false
+		}
+		advances = append(advances, advance)
+	}
+	return advances[0] > 0 && advances[0] == advances[1]
+}
+
+// FontIndex holds the faces of the fonts found in the font directories
+type FontIndex struct {
+	Fonts []FontInfo `json:"fonts"`
+}
+
+// fontSearchDepth is how many levels of subdirectories of a font directory
+// are searched
+const fontSearchDepth = 2
+
+// indexedDir holds the fonts found under a font directory, with the
+// modification times of the directories searched to tell when it is stale
+type indexedDir struct {
+	MTimes map[string]int64 `json:"mtimes"`
+	Fonts  []FontInfo       `json:"fonts"`
+}
+
+// indexDir reads the metadata of every font file under dir. Files that
+// cannot be parsed are skipped.
+func indexDir(dir string) *indexedDir {
+	indexed := &indexedDir{MTimes: map[string]int64{dir: 0}}
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Continue walking even if there's an error
+		}
+		if d.IsDir() {
+			relPath, _ := filepath.Rel(dir, path)
+			if relPath != "." && strings.Count(relPath, string(filepath.Separator)) >= fontSearchDepth {
+				return filepath.SkipDir
+			}
+			if info, err := d.Info(); err == nil {
+				indexed.MTimes[path] = info.ModTime().UnixNano()
+			}
+			return nil
+		}
+		if !isFontFile(d.Name()) {
+			return nil
+		}
+
+		infos, err := readFontInfo(path)
+		if err == nil {
+			indexed.Fonts = append(indexed.Fonts, infos...)
+		}
+		return nil
+	})
+	return indexed
+}
+
+// mergeIndex combines indexed directories in order. Faces found under more
+// than one directory keep their first position.
+func mergeIndex(dirs []*indexedDir) *FontIndex {
+	idx := &FontIndex{}
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		for _, fi := range dir.Fonts {
+			if seen[fi.Spec()] {
+				continue
+			}
+			seen[fi.Spec()] = true
+			idx.Fonts = append(idx.Fonts, fi)
+		}
+	}
+	return idx
+}
+
+var (
+	systemIndexOnce sync.Once
+	systemIndex     *FontIndex
+)
+
+// systemFontIndex returns the index of the system font directories, loaded
+// from the cache or built once per run
+func systemFontIndex() *FontIndex {
+	systemIndexOnce.Do(func() {
+		// A cache that cannot be written only costs the next run a rebuild
+		systemIndex, _ = loadFontIndex(getFontDirectories(), false)
+		// Installed fonts take precedence over the embedded one
+		systemIndex.Fonts = append(systemIndex.Fonts, embeddedFontInfo()...)
+	})
+	return systemIndex
+}
+
+// SystemFonts returns the faces of the fonts in the font directories
+func SystemFonts() []FontInfo {
+	return systemFontIndex().Fonts
+}
+
+// Naming variations a font name can match, in order of preference
+const (
+	MatchFullName       = "full name"
+	MatchPostScriptName = "PostScript name"
+	MatchFamilyStyle    = "family and style"
+	MatchFamily         = "family"
+	MatchFileName       = "file name"
+)
+
+// FontMatch is a face found for a font name, with the naming variation the
+// name matched
+type FontMatch struct {
+	Font      FontInfo
+	Variation string
+}
+
+// normalizeFontName lowercases a name and drops spaces, hyphens and
+// underscores, so that "JetBrains Mono-Bold" and "jetbrainsmono bold" match
+func normalizeFontName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch r {
+		case ' ', '-', '_':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isRegularStyle reports whether a style name is the plain face of a family
+func isRegularStyle(style string) bool {
+	switch normalizeFontName(style) {
+	case "", "regular", "normal", "book", "roman", "plain":
+		return true
+	}
+	return false
+}
+
+// Find looks up a font by name. Names are compared to the full name, the
+// PostScript name, family and style ("Arial Bold"), the family alone
+// (preferring its regular face) and finally the file name.
+func (idx *FontIndex) Find(name string) (FontMatch, bool) {
+	query := normalizeFontName(name)
+	if query == "" {
+		return FontMatch{}, false
+	}
+
+	find := func(variation string, matches func(fi FontInfo) bool) (FontMatch, bool) {
+		for _, fi := range idx.Fonts {
+			if matches(fi) {
+				return FontMatch{Font: fi, Variation: variation}, true
+			}
+		}
+		return FontMatch{}, false
+	}
+
+	if m, ok := find(MatchFullName, func(fi FontInfo) bool {
+		return normalizeFontName(fi.FullName) == query
+	}); ok {
+		return m, true
+	}
+	if m, ok := find(MatchPostScriptName, func(fi FontInfo) bool {
+		return normalizeFontName(fi.PostScriptName) == query
+	}); ok {
+		return m, true
+	}
+	if m, ok := find(MatchFamilyStyle, func(fi FontInfo) bool {
+		return normalizeFontName(fi.Family+fi.Style) == query ||
+			(fi.LegacyFamily != "" && normalizeFontName(fi.LegacyFamily+fi.LegacyStyle) == query)
+	}); ok {
+		return m, true
+	}
+	if m, ok := idx.findFamily(query); ok {
+		return m, true
+	}
+	return find(MatchFileName, func(fi FontInfo) bool {
+		base := filepath.Base(fi.Path)
+		return normalizeFontName(strings.TrimSuffix(base, filepath.Ext(base))) == query
+	})
+}
+
+// findFamily returns the face of a family closest to regular: a regular
+// style if there is one, otherwise the weight nearest to 400
+func (idx *FontIndex) findFamily(query string) (FontMatch, bool) {
+	best, found := FontInfo{}, false
+	score := func(fi FontInfo) int {
+		if isRegularStyle(fi.Style) {
+			return -1
+		}
+		distance := fi.Weight - 400
+		if distance < 0 {
+			distance = -distance
+		}
+		if strings.Contains(strings.ToLower(fi.Style), "italic") || strings.Contains(strings.ToLower(fi.Style), "oblique") {
+			distance += 1000
+		}
+		return distance
+	}
+
+	for _, fi := range idx.Fonts {
+		if normalizeFontName(fi.Family) != query && normalizeFontName(fi.LegacyFamily) != query {
+			continue
+		}
+		if !found || score(fi) < score(best) {
+			best, found = fi, true
+		}
+	}
+	return FontMatch{Font: best, Variation: MatchFamily}, found
+}
+
+// Suggest returns up to n names of indexed fonts that resemble a name, the
+// closest first
+func (idx *FontIndex) Suggest(name string, n int) []string {
+	query := normalizeFontName(name)
+	if query == "" {
+		return nil
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	seen := map[string]bool{}
+	for _, fi := range idx.Fonts {
+		display := fi.Name()
+		if seen[display] {
+			continue
+		}
+		seen[display] = true
+
+		full, family := normalizeFontName(display), normalizeFontName(fi.Family)
+		distance := min(editDistance(query, full), editDistance(query, family))
+		if strings.Contains(full, query) {
+			distance = min(distance, 1)
+		}
+		// Names starting with a family, such as a style the family lacks
+		familyPrefix := len(family) >= 4 && strings.HasPrefix(query, family)
+		if distance <= max(2, len(query)/3) || familyPrefix {
+			candidates = append(candidates, candidate{display, distance})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var names []string
+	for _, c := range candidates {
+		if len(names) == n {
+			break
+		}
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,183 @@
+package output
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseFontInfo(t *testing.T) {
+	goMono := FontInfo{Family: "Go Mono", Style: "Regular", FullName: "Go Mono", PostScriptName: "GoMono", Weight: 400, Monospace: true}
+
+	tests := []struct {
+		name string
+		data []byte
+		want []string // specs of the faces
+	}{
+		{"single font", embeddedFontData, []string{"Mono.ttf"}},
+		{"collection", collectionData(embeddedFontData, 3), []string{"Mono.ttf", "Mono.ttf#1", "Mono.ttf#2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos, err := parseFontInfo("Mono.ttf", tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var specs []string
+			for i, info := range infos {
+				specs = append(specs, info.Spec())
+				want := goMono
+				want.Path, want.Index = "Mono.ttf", i
+				if info != want {
+					t.Errorf("face %d = %+v, want %+v", i, info, want)
+				}
+			}
+			if !reflect.DeepEqual(specs, tt.want) {
+				t.Errorf("specs = %v, want %v", specs, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseFontInfo("Broken.ttf", []byte("ttf")); err == nil {
+		t.Error("parseFontInfo of invalid data succeeded, want error")
+	}
+}
+
+func TestIndexDir(t *testing.T) {
+	dir := writeFontFiles(t, map[string][]byte{
+		"Mono.ttf":         embeddedFontData,
+		"Broken.ttf":       []byte("not a font"),
+		"notes.txt":        embeddedFontData,
+		"sub/CJK.ttc":      collectionData(embeddedFontData, 2),
+		"a/b/Deep.otf":     embeddedFontData,
+		"a/b/c/Deeper.ttf": embeddedFontData,
+	})
+
+	indexed := indexDir(dir)
+	var specs []string
+	for _, fi := range indexed.Fonts {
+		rel, _ := filepath.Rel(dir, fi.Spec())
+		specs = append(specs, filepath.ToSlash(rel))
+	}
+	want := []string{"Mono.ttf", "a/b/Deep.otf", "sub/CJK.ttc", "sub/CJK.ttc#1"}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("indexed fonts = %v, want %v", specs, want)
+	}
+
+	var searched []string
+	for path := range indexed.MTimes {
+		rel, _ := filepath.Rel(dir, path)
+		searched = append(searched, filepath.ToSlash(rel))
+	}
+	sort.Strings(searched)
+	if want := []string{".", "a", "a/b", "sub"}; !reflect.DeepEqual(searched, want) {
+		t.Errorf("searched directories = %v, want %v", searched, want)
+	}
+}
+
+func TestMergeIndex(t *testing.T) {
+	a := FontInfo{Path: "/fonts/A.ttf", Family: "A"}
+	b := FontInfo{Path: "/fonts/B.ttc", Family: "B"}
+	b1 := FontInfo{Path: "/fonts/B.ttc", Index: 1, Family: "B"}
+	c := FontInfo{Path: "/usr/C.ttf", Family: "C"}
+
+	idx := mergeIndex([]*indexedDir{{Fonts: []FontInfo{a, b}}, {Fonts: []FontInfo{b1, a, c}}})
+	if want := []FontInfo{a, b, b1, c}; !reflect.DeepEqual(idx.Fonts, want) {
+		t.Errorf("merged fonts = %+v, want %+v", idx.Fonts, want)
+	}
+}
+
+// testIndex holds fonts with the naming variations Find matches
+var testIndex = &FontIndex{Fonts: []FontInfo{
+	{Path: "/fonts/arial.ttf", Family: "Arial", Style: "Regular", FullName: "Arial", PostScriptName: "ArialMT", Weight: 400},
+	{Path: "/fonts/arialbd.ttf", Family: "Arial", Style: "Bold", FullName: "Arial Bold", PostScriptName: "Arial-BoldMT", Weight: 700},
+	{Path: "/fonts/ariblk.ttf", Family: "Arial", Style: "Black", FullName: "Arial Black", PostScriptName: "Arial-Black",
+		LegacyFamily: "Arial Black", LegacyStyle: "Regular", Weight: 900},
+	{Path: "/fonts/Inter-Italic.otf", Family: "Inter", Style: "Italic", FullName: "Inter Italic", Weight: 400},
+	{Path: "/fonts/Inter-SemiBold.otf", Family: "Inter", Style: "SemiBold", FullName: "Inter SemiBold", Weight: 600},
+	{Path: "/fonts/NotoSansCJK.ttc", Index: 1, Family: "Noto Sans CJK JP", Style: "Regular", Weight: 400},
+	{Path: "/fonts/odd_name.ttf", Family: "Odd", Style: "Book", Weight: 400},
+}}
+
+func TestFontIndexFind(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		variation string
+	}{
+		{"arial", "/fonts/arial.ttf", MatchFullName},
+		{"ARIAL-BOLD", "/fonts/arialbd.ttf", MatchFullName},
+		{"Arial Black", "/fonts/ariblk.ttf", MatchFullName},
+		{"Arial_BoldMT", "/fonts/arialbd.ttf", MatchPostScriptName},
+		{"Arial Black Regular", "/fonts/ariblk.ttf", MatchFamilyStyle},
+		{"Noto Sans CJK JP Regular", "/fonts/NotoSansCJK.ttc#1", MatchFamilyStyle},
+		{"Noto Sans CJK JP", "/fonts/NotoSansCJK.ttc#1", MatchFamily},
+		{"Odd", "/fonts/odd_name.ttf", MatchFamily},
+		// Without a regular face the weight nearest to 400 wins, upright first
+		{"Inter", "/fonts/Inter-SemiBold.otf", MatchFamily},
+		{"odd-name", "/fonts/odd_name.ttf", MatchFileName},
+		{"arialbd", "/fonts/arialbd.ttf", MatchFileName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := testIndex.Find(tt.name)
+			if !ok {
+				t.Fatalf("Find(%q) found nothing", tt.name)
+			}
+			if m.Font.Spec() != tt.spec || m.Variation != tt.variation {
+				t.Errorf("Find(%q) = %s by %s, want %s by %s", tt.name, m.Font.Spec(), m.Variation, tt.spec, tt.variation)
+			}
+		})
+	}
+
+	for _, name := range []string{"", " - ", "Helvetica", "Inter Bold"} {
+		if m, ok := testIndex.Find(name); ok {
+			t.Errorf("Find(%q) = %s, want no match", name, m.Font.Spec())
+		}
+	}
+}
+
+func TestFontIndexSuggest(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"Ariel", 3, []string{"Arial", "Arial Black", "Arial Bold"}},
+		{"Ariel", 1, []string{"Arial"}},
+		{"Inter Bold", 3, []string{"Inter Italic", "Inter SemiBold"}},
+		{"CJK", 3, []string{"Noto Sans CJK JP Regular"}},
+		{"Helvetica", 3, nil},
+		{"", 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testIndex.Suggest(tt.name, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q, %d) = %q, want %q", tt.name, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"arial", "arial", 0},
+		{"ariel", "arial", 1},
+		{"", "mono", 4},
+		{"kitten", "sitting", 3},
+		{"ü", "u", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
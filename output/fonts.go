@@ -63,172 +63,171 @@ func getFontDirectories() []string {
 		}
 	}
 
+	// Elsewhere fontconfig directories come first, followed by the XDG data
+	// directories
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		var configured []string
+		if fc := systemFontconfig(); fc != nil {
+			configured = fc.dirs
+		}
+		dirs = append(append(configured, dirs...), xdgFontDirs()...)
+	}
+
 	// Add current directory and local fonts directory
 	dirs = append([]string{"./fonts", "."}, dirs...)
 
-	return dirs
-}
-
-// findSystemFont searches for a font file in system directories
-func findSystemFont(fontNames []string) (string, error) {
-	dirs := getFontDirectories()
-
-	// ONLY use TTF files - freetype library can't handle OTF properly
-	extensions := []string{".ttf", ".TTF"}
-
+	// Drop directories listed twice
+	var unique []string
+	seen := map[string]bool{}
 	for _, dir := range dirs {
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			continue
+		if dir = absDir(dir); !seen[dir] {
+			seen[dir] = true
+			unique = append(unique, dir)
 		}
+	}
+	return unique
+}
 
-		for _, fontName := range fontNames {
-			// Try different naming conventions
-			namingVariations := []string{
-				fontName,
-				strings.ReplaceAll(fontName, " ", ""),
-				strings.ReplaceAll(fontName, " ", "-"),
-				strings.ReplaceAll(fontName, " ", "_"),
-				strings.ToLower(strings.ReplaceAll(fontName, " ", "")),
-				strings.ToLower(strings.ReplaceAll(fontName, " ", "-")),
-			}
+// absDir returns the absolute form of a directory, so that relative
+// directories such as ./fonts are told apart by working directory
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return filepath.Clean(dir)
+}
 
-			// Add Source Code Pro specific variations
-			if strings.Contains(strings.ToLower(fontName), "source code pro") {
-				if strings.Contains(strings.ToLower(fontName), "bold") {
-					namingVariations = append(namingVariations, "SourceCodePro-Bold", "SourceCodePro-Semibold")
-				} else {
-					namingVariations = append(namingVariations, "SourceCodePro-Regular")
-				}
-			}
+// FontLookup describes how a font name resolves
+type FontLookup struct {
+	Name string // the name that matched
+	Font FontInfo
+	// Variation is the naming variation of the font the name matched, or
+	// MatchPath for font file paths
+	Variation string
+	// Alias is the fontconfig alias or generic family preference that
+	// matched when the name itself is not installed
+	Alias string
+}
 
-			for _, name := range namingVariations {
-				for _, ext := range extensions {
-					fontPath := filepath.Join(dir, name+ext)
-					if _, err := os.Stat(fontPath); err == nil {
-						return fontPath, nil
-					}
-				}
+// MatchPath is the variation of font names that are paths to font files
+const MatchPath = "file path"
+
+// lookupFont finds the first of the font names in the system font index.
+// Names that are not installed are then tried through their fontconfig
+// aliases, so generic families such as "monospace" resolve to the
+// preferred font.
+func lookupFont(fontNames []string) (FontLookup, bool) {
+	idx := systemFontIndex()
+	for _, fontName := range fontNames {
+		if m, ok := idx.Find(fontName); ok {
+			return FontLookup{Name: fontName, Font: m.Font, Variation: m.Variation}, true
+		}
+	}
+	for _, fontName := range fontNames {
+		for _, substitute := range fontSubstitutes(fontName) {
+			if m, ok := idx.Find(substitute); ok {
+				return FontLookup{Name: fontName, Font: m.Font, Variation: m.Variation, Alias: substitute}, true
 			}
 		}
+	}
 
-		// Also search recursively in subdirectories (limited depth) - TTF ONLY
-		if foundPath := searchFontInSubdirs(dir, fontNames, 2); foundPath != "" {
-			return foundPath, nil
+	// Aliases name families, so "Arial Bold" tries the aliases of "Arial"
+	// in bold
+	for _, fontName := range fontNames {
+		family, style := splitStyle(fontName)
+		if style == "" {
+			continue
+		}
+		for _, substitute := range fontSubstitutes(family) {
+			if m, ok := idx.Find(substitute + " " + style); ok {
+				return FontLookup{Name: fontName, Font: m.Font, Variation: m.Variation, Alias: substitute + " " + style}, true
+			}
 		}
 	}
+	return FontLookup{}, false
+}
 
-	return "", fmt.Errorf("font not found: tried %v", fontNames)
+// styleWords are the words of style names that can end a font name
+var styleWords = map[string]bool{
+	"regular": true, "book": true, "bold": true, "italic": true, "oblique": true,
+	"thin": true, "light": true, "medium": true, "semibold": true, "demibold": true,
+	"extrabold": true, "black": true, "heavy": true, "condensed": true,
 }
 
-// searchFontInSubdirs searches for fonts in subdirectories up to maxDepth
-func searchFontInSubdirs(baseDir string, fontNames []string, maxDepth int) string {
-	if maxDepth <= 0 {
-		return ""
+// splitStyle splits the trailing style words from a font name, as in
+// "Arial Bold Italic"
+func splitStyle(name string) (family, style string) {
+	words := strings.Fields(name)
+	i := len(words)
+	for i > 1 && styleWords[strings.ToLower(words[i-1])] {
+		i--
 	}
+	return strings.Join(words[:i], " "), strings.Join(words[i:], " ")
+}
 
-	// ONLY TTF files
-	extensions := []string{".ttf", ".TTF"}
-	var foundPath string
+// findSystemFont returns the first of the font names found on the system,
+// as a path that loads the matched face
+func findSystemFont(fontNames []string) (string, error) {
+	if lookup, ok := lookupFont(fontNames); ok {
+		return lookup.Font.Spec(), nil
+	}
+	return "", fmt.Errorf("font not found: tried %v", fontNames)
+}
 
-	filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+// FindFont resolves a font path or system font name the way PNG fonts are
+// resolved. Names that are not found fail with suggestions of similar
+// installed fonts.
+func FindFont(name string) (FontLookup, error) {
+	if path, index := splitFaceIndex(name); fileExists(path) {
+		infos, err := readFontInfo(path)
 		if err != nil {
-			return nil // Continue walking even if there's an error
-		}
-
-		// Skip if we're too deep
-		relPath, _ := filepath.Rel(baseDir, path)
-		if strings.Count(relPath, string(filepath.Separator)) > maxDepth {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+			return FontLookup{}, fmt.Errorf("failed to parse font file '%s': %v", path, err)
 		}
-
-		if info.IsDir() {
-			return nil
+		if index >= len(infos) {
+			return FontLookup{}, fmt.Errorf("font file '%s' has %d faces, face index %d is out of range", path, len(infos), index)
 		}
+		return FontLookup{Name: name, Font: infos[index], Variation: MatchPath}, nil
+	}
 
-		filename := info.Name()
-		for _, ext := range extensions {
-			if strings.HasSuffix(filename, ext) {
-				baseFilename := strings.TrimSuffix(filename, ext)
-
-				for _, fontName := range fontNames {
-					namingVariations := []string{
-						fontName,
-						strings.ReplaceAll(fontName, " ", ""),
-						strings.ReplaceAll(fontName, " ", "-"),
-						strings.ReplaceAll(fontName, " ", "_"),
-						strings.ToLower(strings.ReplaceAll(fontName, " ", "")),
-						strings.ToLower(strings.ReplaceAll(fontName, " ", "-")),
-					}
-
-					// Add Source Code Pro specific variations for subdirectory search
-					if strings.Contains(strings.ToLower(fontName), "source code pro") {
-						if strings.Contains(strings.ToLower(fontName), "bold") {
-							namingVariations = append(namingVariations, "SourceCodePro-Bold", "SourceCodePro-Semibold")
-						} else {
-							namingVariations = append(namingVariations, "SourceCodePro-Regular")
-						}
-					}
-
-					for _, name := range namingVariations {
-						if strings.EqualFold(baseFilename, name) {
-							foundPath = path
-							return filepath.SkipAll // Stop walking when found
-						}
-					}
-				}
-			}
+	lookup, ok := lookupFont([]string{name})
+	if !ok {
+		err := fmt.Errorf("font not found: tried [%s]", name)
+		if suggestions := systemFontIndex().Suggest(name, 3); len(suggestions) > 0 {
+			return FontLookup{}, fmt.Errorf("%v. Did you mean: %s?", err, strings.Join(suggestions, ", "))
 		}
-
-		return nil
-	})
-
-	return foundPath
+		return FontLookup{}, err
+	}
+	return lookup, nil
 }
 
-// resolveSystemFont tries to resolve a font path, supporting both explicit paths and system font names
+// resolveSystemFont tries to resolve a font path, supporting both explicit
+// paths and system font names. The result may carry a #N face index.
 func resolveSystemFont(fontPath string) (string, error) {
 	// If it's already a valid file path, use it
-	if _, err := os.Stat(fontPath); err == nil {
+	if path, _ := splitFaceIndex(fontPath); fileExists(path) {
 		return fontPath, nil
 	}
 
-	// Check if it's a relative path that might exist
-	if !filepath.IsAbs(fontPath) {
-		if _, err := os.Stat(fontPath); err == nil {
-			return fontPath, nil
-		}
-	}
-
 	// Try to find it as a system font name
-	return findSystemFont([]string{fontPath})
+	lookup, err := FindFont(fontPath)
+	if err != nil {
+		return "", err
+	}
+	return lookup.Font.Spec(), nil
 }
 
-// getDefaultSystemFonts returns default system fonts with fallbacks
-func getDefaultSystemFonts() (string, string, string, error) {
-	// Try to find system fonts
-	sansSerifPath, err1 := findSystemFont(SystemSansSerif.Names)
-	boldPath, err2 := findSystemFont(SystemSansSerifBold.Names)
-	monospacePath, err3 := findSystemFont(SystemMonospace.Names)
-
-	// If we can't find system fonts, provide helpful error message
-	if err1 != nil && err2 != nil && err3 != nil {
-		return "", "", "", fmt.Errorf("no system fonts found. Please specify font paths in your JSON config or place TTF files in ./fonts/ directory. Searched for: %v, %v, %v",
-			SystemSansSerif.Names, SystemSansSerifBold.Names, SystemMonospace.Names)
-	}
+// fileExists reports whether a path names an existing file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
 
-	// Use fallbacks if some fonts are missing
-	if err1 != nil {
-		sansSerifPath = boldPath // Use bold as fallback for regular
+// getDefaultMonospaceFont returns the preferred installed monospace font
+func getDefaultMonospaceFont() (string, error) {
+	path, err := findSystemFont([]string{"monospace"})
+	if err != nil {
+		return "", fmt.Errorf("no monospace system font found. Please set ascii_font in your JSON config or place TTF, OTF or TTC files in ./fonts/ directory. Searched for: %v",
+			SystemMonospace.Names)
 	}
-	if err2 != nil {
-		boldPath = sansSerifPath // Use regular as fallback for bold
-	}
-	if err3 != nil {
-		monospacePath = sansSerifPath // Use sans-serif as fallback for monospace
-	}
-
-	return sansSerifPath, boldPath, monospacePath, nil
+	return path, nil
 }
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindFontPath(t *testing.T) {
+	dir := writeFontFiles(t, map[string][]byte{
+		"Mono.ttf":   embeddedFontData,
+		"Mono.ttc":   collectionData(embeddedFontData, 2),
+		"Broken.ttf": []byte("not a font"),
+	})
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{path("Mono.ttf"), path("Mono.ttf"), ""},
+		{path("Mono.ttc"), path("Mono.ttc"), ""},
+		{path("Mono.ttc#1"), path("Mono.ttc#1"), ""},
+		{path("Mono.ttc#2"), "", "has 2 faces, face index 2 is out of range"},
+		{path("Mono.ttf#1"), "", "has 1 faces, face index 1 is out of range"},
+		{path("Broken.ttf"), "", "failed to parse font file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.name), func(t *testing.T) {
+			lookup, err := FindFont(tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FindFont(%q) error = %v, want %q", tt.name, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lookup.Font.Spec() != tt.spec || lookup.Variation != MatchPath || lookup.Font.Family != "Go Mono" {
+				t.Errorf("FindFont(%q) = %+v, want %s by %s", tt.name, lookup, tt.spec, MatchPath)
+			}
+		})
+	}
+}
+
+func TestFindFontName(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// The embedded font is indexed after the installed fonts
+	lookup, err := FindFont("go-mono")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lookup.Font.Family != "Go Mono" || lookup.Name != "go-mono" {
+		t.Errorf(`FindFont("go-mono") = %+v`, lookup)
+	}
+
+	_, err = FindFont("Qzxv Unfindable Sans")
+	if err == nil {
+		t.Fatal("FindFont of an unknown name succeeded, want error")
+	}
+	if want := "font not found: tried [Qzxv Unfindable Sans]"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("error = %q, want it to start with %q", err, want)
+	}
+}
+
+func TestSplitStyle(t *testing.T) {
+	tests := []struct {
+		name, family, style string
+	}{
+		{"Arial", "Arial", ""},
+		{"Arial Bold", "Arial", "Bold"},
+		{"DejaVu Sans Bold Italic", "DejaVu Sans", "Bold Italic"},
+		{"Arial Black", "Arial", "Black"},
+		{"Bold", "Bold", ""},
+		{"  Noto   Sans  regular ", "Noto Sans", "regular"},
+	}
+	for _, tt := range tests {
+		if family, style := splitStyle(tt.name); family != tt.family || style != tt.style {
+			t.Errorf("splitStyle(%q) = %q, %q, want %q, %q", tt.name, family, style, tt.family, tt.style)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package output
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Default PNG spacing, in pixels
+const (
+	DefaultMargin      = 50
+	DefaultPaddingX    = 0
+	DefaultPaddingY    = 0
+	DefaultBorderWidth = 1
+)
+
+// DefaultLineSpacing is the default multiplier of the text line height
+const DefaultLineSpacing = 1.0
+
+// spacing holds the resolved spacing options of a PNG image, in pixels
+type spacing struct {
+	margin      int
+	paddingX    int
+	paddingY    int
+	lineSpacing float64
+	border      int
+	scale       float64
+	// strokes draws borders as lines of the border width that stretch with
+	// the padding. Otherwise they are the box drawing glyphs of the font,
+	// laid out like the text.
+	strokes bool
+}
+
+// spacing resolves the spacing options of a configuration
+func (c PNGConfig) spacing() spacing {
+	s := spacing{
+		margin:      DefaultMargin,
+		paddingX:    DefaultPaddingX,
+		paddingY:    DefaultPaddingY,
+		lineSpacing: DefaultLineSpacing,
+		border:      DefaultBorderWidth,
+		scale:       1,
+	}
+	if c.Margin != nil {
+		s.margin = max(*c.Margin, 0)
+	}
+	if c.PaddingX != nil {
+		s.paddingX = max(*c.PaddingX, 0)
+	}
+	if c.PaddingY != nil {
+		s.paddingY = max(*c.PaddingY, 0)
+	}
+	if c.LineSpacing != nil && *c.LineSpacing > 0 {
+		s.lineSpacing = *c.LineSpacing
+	}
+	if c.BorderWidth != nil && *c.BorderWidth > 0 {
+		s.border = *c.BorderWidth
+	}
+	// With the default spacing, given or not, images keep the text layout
+	// of the font
+	s.strokes = s.paddingX != DefaultPaddingX || s.paddingY != DefaultPaddingY ||
+		s.lineSpacing != DefaultLineSpacing || s.border != DefaultBorderWidth
+	return s
+}
+
+// scaled multiplies the pixel values by a scale factor. Strokes stay at
+// least one pixel wide.
+func (s spacing) scaled(factor float64) spacing {
+	px := func(v int) int {
+		return int(math.Round(float64(v) * factor))
+	}
+	s.margin = px(s.margin)
+	s.paddingX = px(s.paddingX)
+	s.paddingY = px(s.paddingY)
+	s.border = max(px(s.border), 1)
+	s.scale *= factor
+	return s
+}
+
+// stroke returns the width of thin decorations such as underlines and the
+// offset of synthetic bold
+func (s spacing) stroke() int {
+	return max(int(math.Round(s.scale)), 1)
+}
+
+// boxArms describes the lines of a box-drawing character leaving its
+// center: up, right, down and left. 1 is a single line, 2 a double line.
+type boxArms [4]uint8
+
+const (
+	armUp = iota
+	armRight
+	armDown
+	armLeft
+)
+
+// boxChars maps the box-drawing characters used by table styles to their arms
+var boxChars = map[rune]boxArms{
+	'─': {0, 1, 0, 1}, '│': {1, 0, 1, 0},
+	'┌': {0, 1, 1, 0}, '┐': {0, 0, 1, 1}, '└': {1, 1, 0, 0}, '┘': {1, 0, 0, 1},
+	'╭': {0, 1, 1, 0}, '╮': {0, 0, 1, 1}, '╰': {1, 1, 0, 0}, '╯': {1, 0, 0, 1},
+	'├': {1, 1, 1, 0}, '┤': {1, 0, 1, 1}, '┬': {0, 1, 1, 1}, '┴': {1, 1, 0, 1}, '┼': {1, 1, 1, 1},
+
+	'═': {0, 2, 0, 2}, '║': {2, 0, 2, 0},
+	'╔': {0, 2, 2, 0}, '╗': {0, 0, 2, 2}, '╚': {2, 2, 0, 0}, '╝': {2, 0, 0, 2},
+	'╠': {2, 2, 2, 0}, '╣': {2, 0, 2, 2}, '╦': {0, 2, 2, 2}, '╩': {2, 2, 0, 2}, '╬': {2, 2, 2, 2},
+
+	'╞': {1, 2, 1, 0}, '╡': {1, 0, 1, 2}, '╤': {0, 2, 1, 2}, '╧': {1, 2, 0, 2}, '╪': {1, 2, 1, 2},
+	'╟': {2, 1, 2, 0}, '╢': {2, 0, 2, 1}, '╥': {0, 1, 2, 1}, '╨': {2, 1, 0, 1}, '╫': {2, 1, 2, 1},
+}
+
+// gridCell is a single character of the table text
+type gridCell struct {
+	r     rune
+	typ   TextType
+	style TextStyle
+}
+
+// grid lays out the table text as character columns and lines. When
+// borders are strokes, columns holding vertical borders and lines holding
+// only borders are as wide as the strokes; the other columns are one
+// character wide and the other lines one line high, plus padding. Glyph
+// borders are laid out like text.
+type grid struct {
+	lines   [][]gridCell
+	roles   []lineRole
+	colX    []int // left edge of each column
+	colW    []int
+	textX   []fixed.Int26_6 // left edge of the glyph in each column
+	rowY    []int           // top edge of each line
+	rowH    []int
+	border  []uint8  // line kind of each border column, 0 for text columns
+	spans   [][2]int // outer border columns of lines spanning columns, or -1
+	width   int
+	height  int
+	advance int
+	ascent  int
+	lineH   int
+}
+
+// newGrid measures the layout of the table text in the given face
+func newGrid(segments [][]TextSegment, face font.Face, s spacing) *grid {
+	g := &grid{roles: lineRoles(segments)}
+	for _, lineSegs := range segments {
+		var line []gridCell
+		for _, seg := range lineSegs {
+			for _, r := range seg.Text {
+				line = append(line, gridCell{r: r, typ: seg.Type, style: seg.Style})
+			}
+		}
+		g.lines = append(g.lines, line)
+	}
+
+	columns := 0
+	for _, line := range g.lines {
+		columns = max(columns, len(line))
+	}
+
+	// Vertical borders are the columns where text lines hold a box
+	// character with an upward or downward line
+	g.border = make([]uint8, columns)
+	for i, line := range g.lines {
+		if g.roles[i] == borderLine || !s.strokes {
+			continue
+		}
+		for col, cell := range line {
+			if arms, ok := boxChars[cell.r]; ok {
+				g.border[col] = max(g.border[col], arms[armUp], arms[armDown])
+			}
+		}
+	}
+
+	adv, _ := face.GlyphAdvance('M')
+	g.advance = adv.Round()
+	metrics := face.Metrics()
+	g.ascent = metrics.Ascent.Ceil()
+	g.lineH = (metrics.Ascent + metrics.Descent).Ceil()
+
+	strokeWidth := func(kind uint8) int {
+		if kind == 2 {
+			return 3 * s.border
+		}
+		return s.border
+	}
+
+	g.colW = make([]int, columns)
+	padBefore := make([]int, columns)
+	for col := range g.border {
+		if g.border[col] > 0 {
+			g.colW[col] = strokeWidth(g.border[col])
+			continue
+		}
+		g.colW[col] = g.advance
+		// Cells are padded next to the borders around them
+		if col > 0 && g.border[col-1] > 0 {
+			g.colW[col] += s.paddingX
+			padBefore[col] = s.paddingX
+		}
+		if col+1 < columns && g.border[col+1] > 0 {
+			g.colW[col] += s.paddingX
+		}
+	}
+
+	// Text of spanning lines (section titles, placeholders) runs across
+	// the narrower border columns, so widen the last column if it overflows
+	g.spans = make([][2]int, len(g.lines))
+	for i, line := range g.lines {
+		g.spans[i] = [2]int{-1, -1}
+		if g.roles[i] != borderLine {
+			g.spans[i] = g.lineSpan(line)
+		}
+		left, right := g.spans[i][0], g.spans[i][1]
+		if left < 0 {
+			continue
+		}
+		available, chars := 0, 0
+		for col := left + 1; col < right; col++ {
+			available += g.colW[col]
+			if line[col].r != ' ' {
+				chars = col - left + 1 // up to here, plus a trailing space
+			}
+		}
+		if extra := chars*g.advance + 2*s.paddingX - available; chars > 0 && extra > 0 {
+			g.colW[right-1] += extra
+		}
+	}
+
+	g.colX = make([]int, columns)
+	g.textX = make([]fixed.Int26_6, columns)
+	if s.strokes {
+		x := 0
+		for col := range g.colW {
+			g.colX[col] = x
+			g.textX[col] = fixed.I(x + padBefore[col])
+			x += g.colW[col]
+		}
+		g.width = x
+	} else {
+		// Glyphs follow the unrounded advance, as when drawing a string
+		for col := range g.colW {
+			g.textX[col] = adv * fixed.Int26_6(col)
+			g.colX[col] = g.textX[col].Floor()
+		}
+		g.width = (adv * fixed.Int26_6(columns)).Floor()
+		for col := range g.colW {
+			next := g.width
+			if col+1 < columns {
+				next = g.colX[col+1]
+			}
+			g.colW[col] = next - g.colX[col]
+		}
+	}
+
+	textHeight := int(math.Round(float64(g.lineH)*s.lineSpacing)) + 2*s.paddingY
+	y := 0
+	for i, line := range g.lines {
+		g.rowY = append(g.rowY, y)
+		h := textHeight
+		if g.roles[i] == borderLine && s.strokes {
+			kind := uint8(1)
+			for _, cell := range line {
+				if arms, ok := boxChars[cell.r]; ok {
+					kind = max(kind, arms[armLeft], arms[armRight])
+				}
+			}
+			h = strokeWidth(kind)
+		}
+		g.rowH = append(g.rowH, h)
+		y += h
+	}
+	g.height = y
+
+	return g
+}
+
+// isBorder reports whether a character of a line is a vertical border
+func (g *grid) isBorder(line []gridCell, col int) bool {
+	if g.border[col] > 0 {
+		return true
+	}
+	arms, ok := boxChars[line[col].r]
+	return ok && (arms[armUp] > 0 || arms[armDown] > 0)
+}
+
+// lineSpan returns the outer border columns of a text line that runs across
+// border columns, or -1 when the line follows the columns
+func (g *grid) lineSpan(line []gridCell) [2]int {
+	left, right, spanning := -1, -1, false
+	for col, cell := range line {
+		if g.border[col] == 0 {
+			continue
+		}
+		if _, ok := boxChars[cell.r]; !ok {
+			spanning = true
+			continue
+		}
+		if left < 0 {
+			left = col
+		}
+		right = col
+	}
+	if !spanning || left < 0 || right <= left {
+		return [2]int{-1, -1}
+	}
+	return [2]int{left, right}
+}
+
+// cellRect returns the rectangle of a character, offset by the origin
+func (g *grid) cellRect(line, col int, origin image.Point) image.Rectangle {
+	return image.Rect(g.colX[col], g.rowY[line], g.colX[col]+g.colW[col], g.rowY[line]+g.rowH[line]).Add(origin)
+}
+
+// draw renders the grid with its top left corner at origin. Each character
+// is drawn with the first face of the chain that has its glyph.
+func (g *grid) draw(img *image.RGBA, faces *faceChain, origin image.Point, colors palette, s spacing) {
+	for i, line := range g.lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		// Row fills span the line between the middle of its outer borders
+		var fill *color.RGBA
+		switch g.roles[i] {
+		case headerLine:
+			fill = colors.headerBackground
+		case zebraLine:
+			fill = colors.zebra
+		}
+		if fill != nil {
+			first, last := g.cellRect(i, 0, origin), g.cellRect(i, len(line)-1, origin)
+			row := image.Rect(first.Min.X, first.Min.Y, last.Max.X, first.Max.Y)
+			if g.isBorder(line, 0) {
+				row.Min.X = (first.Min.X + first.Max.X) / 2
+			}
+			if g.isBorder(line, len(line)-1) {
+				row.Max.X = (last.Min.X + last.Max.X) / 2
+			}
+			draw.Draw(img, row, image.NewUniform(*fill), image.Point{}, draw.Over)
+		}
+
+		baseline := origin.Y + g.rowY[i] + (g.rowH[i]-g.lineH)/2 + g.ascent
+		left, right := g.spans[i][0], g.spans[i][1]
+		for col, cell := range line {
+			rect := g.cellRect(i, col, origin)
+			arms, isBox := boxChars[cell.r]
+			inSpan := col > left && col < right
+			if isBox && !inSpan && s.strokes && (g.roles[i] == borderLine || g.border[col] > 0) {
+				drawBox(img, rect, arms, colors.border, s.border)
+				continue
+			}
+
+			// Spanning text flows one advance per character from the
+			// left border
+			x := fixed.I(origin.X) + g.textX[col]
+			if inSpan {
+				left := origin.X + g.colX[left] + g.colW[left] + s.paddingX + (col-left-1)*g.advance
+				x = fixed.I(left)
+				rect = image.Rect(left, rect.Min.Y, left+g.advance, rect.Max.Y)
+			} else if g.border[col] > 0 {
+				x = fixed.I(rect.Min.X)
+			}
+
+			if cell.style.Background != nil {
+				draw.Draw(img, rect, image.NewUniform(*cell.style.Background), image.Point{}, draw.Over)
+			}
+
+			text := colors.text
+			switch {
+			case cell.typ == ASCIIText:
+				text = colors.border
+			case g.roles[i] == headerLine:
+				text = colors.headerText
+			}
+			if cell.style.Color != nil {
+				text = *cell.style.Color
+			}
+
+			drawGlyph(img, faces.face(cell.r), cell, text, x, baseline, g.advance, s.stroke())
+		}
+	}
+}
+
+// drawGlyph draws a single character on a baseline. Bold text is drawn
+// twice one stroke apart so that it keeps the width of the face, and italic
+// text is slanted.
+func drawGlyph(img *image.RGBA, face font.Face, cell gridCell, text color.RGBA, x fixed.Int26_6, baseline, advance, stroke int) {
+	if cell.r == ' ' && !cell.style.Underline {
+		return
+	}
+
+	src := image.NewUniform(text)
+	drawGlyphAt(img, face, cell, src, x, baseline)
+	if cell.style.Bold {
+		drawGlyphAt(img, face, cell, src, x+fixed.I(stroke), baseline)
+	}
+
+	if cell.style.Underline {
+		left := x.Floor()
+		underline := image.Rect(left, baseline+stroke, left+advance, baseline+2*stroke)
+		draw.Draw(img, underline, src, image.Point{}, draw.Over)
+	}
+}
+
+// italicSlant is the horizontal shift per pixel of height of italic text
+const italicSlant = 0.2
+
+// drawGlyphAt draws the glyph of a cell with its origin at x on the
+// baseline. Italic glyphs are drawn row by row, each shifted right by its
+// height above the baseline.
+func drawGlyphAt(img *image.RGBA, face font.Face, cell gridCell, src image.Image, x fixed.Int26_6, baseline int) {
+	dr, mask, maskp, _, ok := face.Glyph(fixed.Point26_6{X: x, Y: fixed.I(baseline)}, cell.r)
+	if !ok {
+		return
+	}
+	if !cell.style.Italic {
+		draw.DrawMask(img, dr, src, image.Point{}, mask, maskp, draw.Over)
+		return
+	}
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		shift := int(math.Round(float64(baseline-y) * italicSlant))
+		row := image.Rect(dr.Min.X+shift, y, dr.Max.X+shift, y+1)
+		draw.DrawMask(img, row, src, image.Point{}, mask, maskp.Add(image.Pt(0, y-dr.Min.Y)), draw.Over)
+	}
+}
+
+// drawBox draws the lines of a box-drawing character inside rect as
+// strokes of the given width. Double lines are two strokes one stroke
+// width apart.
+func drawBox(img *image.RGBA, rect image.Rectangle, arms boxArms, c color.RGBA, width int) {
+	src := image.NewUniform(c)
+	cx := (rect.Min.X + rect.Max.X) / 2
+	cy := (rect.Min.Y + rect.Max.Y) / 2
+
+	// offsets returns the positions of the strokes of a line kind
+	offsets := func(kind uint8) []int {
+		if kind == 2 {
+			return []int{-width, width}
+		}
+		return []int{0}
+	}
+	half := width / 2
+
+	for arm, kind := range arms {
+		if kind == 0 {
+			continue
+		}
+		for _, off := range offsets(kind) {
+			var stroke image.Rectangle
+			switch arm {
+			case armUp:
+				stroke = image.Rect(cx+off-half, rect.Min.Y, cx+off-half+width, cy+half+width)
+			case armDown:
+				stroke = image.Rect(cx+off-half, cy-half, cx+off-half+width, rect.Max.Y)
+			case armLeft:
+				stroke = image.Rect(rect.Min.X, cy+off-half, cx+half+width, cy+off-half+width)
+			case armRight:
+				stroke = image.Rect(cx-half, cy+off-half, rect.Max.X, cy+off-half+width)
+			}
+			draw.Draw(img, stroke, src, image.Point{}, draw.Over)
+		}
+	}
+}
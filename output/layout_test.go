@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSpacing(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string // JSON of the png configuration
+		want   spacing
+	}{
+		{
+			name: "defaults",
+			want: spacing{margin: 50, lineSpacing: 1, border: 1, scale: 1},
+		},
+		{
+			name:   "explicit defaults keep glyph borders",
+			config: `{"margin": 50, "padding_x": 0, "padding_y": 0, "line_spacing": 1, "border_width": 1}`,
+			want:   spacing{margin: 50, lineSpacing: 1, border: 1, scale: 1},
+		},
+		{
+			name:   "margin keeps glyph borders",
+			config: `{"margin": 0}`,
+			want:   spacing{lineSpacing: 1, border: 1, scale: 1},
+		},
+		{
+			name:   "padding",
+			config: `{"padding_x": 8}`,
+			want:   spacing{margin: 50, paddingX: 8, lineSpacing: 1, border: 1, scale: 1, strokes: true},
+		},
+		{
+			name:   "line spacing",
+			config: `{"line_spacing": 1.2}`,
+			want:   spacing{margin: 50, lineSpacing: 1.2, border: 1, scale: 1, strokes: true},
+		},
+		{
+			name:   "border width",
+			config: `{"border_width": 2}`,
+			want:   spacing{margin: 50, lineSpacing: 1, border: 2, scale: 1, strokes: true},
+		},
+		{
+			name:   "invalid values use the defaults",
+			config: `{"margin": -5, "padding_y": -1, "line_spacing": 0, "border_width": -2}`,
+			want:   spacing{lineSpacing: 1, border: 1, scale: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config PNGConfig
+			if tt.config != "" {
+				if err := json.Unmarshal([]byte(tt.config), &config); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := config.spacing(); got != tt.want {
+				t.Errorf("spacing() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpacingScaled(t *testing.T) {
+	s := spacing{margin: 50, paddingX: 3, paddingY: 1, lineSpacing: 1.5, border: 1, scale: 1, strokes: true}
+	want := spacing{margin: 75, paddingX: 5, paddingY: 2, lineSpacing: 1.5, border: 2, scale: 1.5, strokes: true}
+	if got := s.scaled(1.5); got != want {
+		t.Errorf("scaled(1.5) = %+v, want %+v", got, want)
+	}
+
+	// Strokes stay at least one pixel wide
+	if got := s.scaled(0.25); got.border != 1 || got.stroke() != 1 {
+		t.Errorf("scaled(0.25) border = %d, stroke = %d, want 1", got.border, got.stroke())
+	}
+}
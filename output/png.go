@@ -3,30 +3,82 @@ package output
 import (
 	"fmt"
 	"image"
-	"image/color"
 	"image/draw"
 	"image/png"
-	"io/ioutil"
 	"os"
-	"regexp"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
-	"github.com/golang/freetype"
-	"github.com/golang/freetype/truetype"
-	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
 )
 
 // PNGConfig contains PNG rendering options
 type PNGConfig struct {
+	// TitleFont and ContentFont are accepted for older configurations but
+	// not used; all table text is drawn with ASCIIFont
 	TitleFont   FontConfig `json:"title_font"`
 	ContentFont FontConfig `json:"content_font"`
 	ASCIIFont   FontConfig `json:"ascii_font"`
+	// Theme sets the image colors, by preset name or as an object
+	Theme *Theme `json:"theme,omitempty"`
+
+	// Hermetic renders with the embedded font only, ignoring the configured
+	// fonts, so that images are identical on every machine
+	Hermetic bool `json:"hermetic,omitempty"`
+
+	// Spacing in pixels. Nil values use the defaults.
+	Margin   *int `json:"margin,omitempty"`
+	PaddingX *int `json:"padding_x,omitempty"`
+	PaddingY *int `json:"padding_y,omitempty"`
+	// LineSpacing multiplies the height of text lines (default 1)
+	LineSpacing *float64 `json:"line_spacing,omitempty"`
+	// BorderWidth is the stroke width of borders (default 1)
+	BorderWidth *int `json:"border_width,omitempty"`
+
+	// DPI sets the resolution fonts are rendered at (default 72, where a
+	// point is a pixel). Scale multiplies it, e.g. 2 for @2x images.
+	DPI   float64 `json:"dpi,omitempty"`
+	Scale float64 `json:"scale,omitempty"`
+	// Scales writes one image per scale factor in a single run, e.g.
+	// [1, 2, 3] writes table.png, table@2x.png and table@3x.png
+	Scales []float64 `json:"scales,omitempty"`
 }
 
+// scaleFactor returns the factor applied to font sizes, spacing and
+// strokes for the given scale
+func (c PNGConfig) scaleFactor(scale float64) float64 {
+	if scale <= 0 {
+		scale = 1
+	}
+	dpi := c.DPI
+	if dpi <= 0 {
+		dpi = 72
+	}
+	return scale * dpi / 72
+}
+
+// ScaledPath inserts the @Nx suffix of a scale before the extension of a
+// path. Scale 1 (or unset) keeps the path unchanged.
+func ScaledPath(path string, scale float64) string {
+	if scale == 1 || scale <= 0 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "@" + strconv.FormatFloat(scale, 'f', -1, 64) + "x" + ext
+}
+
+// DefaultFontSize is the size in points of fonts that set none
+const DefaultFontSize = 12
+
 // FontConfig represents font configuration
 type FontConfig struct {
 	Path string  `json:"path"`
 	Size float64 `json:"size"`
+	// Fallback lists fonts, by path or name, to take glyphs from when the
+	// font lacks them. The first font with the glyph is used.
+	Fallback []string `json:"fallback,omitempty"`
 }
 
 // TextType represents different types of text in the table
@@ -38,280 +90,246 @@ const (
 	ContentText
 )
 
-// TextSegment represents a segment of text with its type
+// TextSegment represents a segment of text with its type and style
 type TextSegment struct {
-	Text string
-	Type TextType
+	Text  string
+	Type  TextType
+	Style TextStyle
 }
 
-// GeneratePNG creates a PNG image from the table text
+// GeneratePNG creates a PNG image from unstyled table text
 func GeneratePNG(tableText string, config PNGConfig, outputPath string) error {
-	// Load fonts with system font support
-	fonts := make(map[TextType]*truetype.Font)
-
-	// Use system defaults if paths are not specified or invalid
-	var asciiPath, titlePath, contentPath string
-	var err error
-
-	if config.ASCIIFont.Path == "" || config.TitleFont.Path == "" || config.ContentFont.Path == "" {
-		// Try to get default system fonts
-		defaultContent, defaultTitle, defaultMono, sysErr := getDefaultSystemFonts()
-		if sysErr != nil {
-			return fmt.Errorf("failed to find system fonts and no paths specified: %v", sysErr)
-		}
+	_, err := GeneratePNGSet(tableText, nil, config, outputPath)
+	return err
+}
 
-		if config.ASCIIFont.Path == "" {
-			asciiPath = defaultMono
-		} else {
-			asciiPath = config.ASCIIFont.Path
-		}
+// PNGResult describes the images written by GeneratePNGSet
+type PNGResult struct {
+	Paths []string
+	// Missing lists the characters no font of the chain could render. They
+	// are drawn with the missing glyph of the main font.
+	Missing []rune
+}
+
+// GeneratePNGSet creates the PNG images of the table text, drawing the
+// cell styles given by spans. With Scales set it writes one image per
+// scale, adding an @Nx suffix to the path for scales other than 1;
+// otherwise it writes a single image at Scale to outputPath.
+func GeneratePNGSet(tableText string, spans []Span, config PNGConfig, outputPath string) (*PNGResult, error) {
+	colors, err := config.Theme.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := loadFonts(config)
+	if err != nil {
+		return nil, err
+	}
+	segments := parseTableText(tableText, spans)
 
-		if config.TitleFont.Path == "" {
-			titlePath = defaultTitle
-		} else {
-			titlePath = config.TitleFont.Path
+	scales := config.Scales
+	if len(scales) == 0 {
+		scales = []float64{config.Scale}
+	}
+
+	result := &PNGResult{Missing: chain.missing(segments, config.spacing().strokes)}
+	seen := map[string]bool{}
+	for _, scale := range scales {
+		path := outputPath
+		if len(config.Scales) > 0 {
+			path = ScaledPath(outputPath, scale)
+		}
+		if seen[path] {
+			continue
 		}
+		seen[path] = true
 
-		if config.ContentFont.Path == "" {
-			contentPath = defaultContent
-		} else {
-			contentPath = config.ContentFont.Path
+		img, err := renderPNG(segments, chain, config, colors, config.scaleFactor(scale))
+		if err != nil {
+			return result, err
+		}
+		if err := savePNG(img, path); err != nil {
+			return result, err
 		}
-	} else {
-		asciiPath = config.ASCIIFont.Path
-		titlePath = config.TitleFont.Path
-		contentPath = config.ContentFont.Path
+		result.Paths = append(result.Paths, path)
 	}
 
-	asciiFont, err := loadFont(asciiPath)
-	if err != nil {
-		return fmt.Errorf("failed to load ASCII font: %v", err)
+	return result, nil
+}
+
+// loadFonts loads the font chain of ascii_font, which draws all table
+// text. A font without a path uses the default system monospace font.
+func loadFonts(config PNGConfig) (*fontChain, error) {
+	if config.Hermetic {
+		return loadFontChain(EmbeddedFont, nil)
 	}
-	fonts[ASCIIText] = asciiFont
 
-	titleFont, err := loadFont(titlePath)
-	if err != nil {
-		return fmt.Errorf("failed to load title font: %v", err)
+	path := config.ASCIIFont.Path
+	if path == "" {
+		// Without system fonts use the embedded font
+		path = EmbeddedFont
+		if defaultMono, err := getDefaultMonospaceFont(); err == nil {
+			path = defaultMono
+		}
 	}
-	fonts[HeaderText] = titleFont
 
-	contentFont, err := loadFont(contentPath)
+	chain, err := loadFontChain(path, config.ASCIIFont.Fallback)
 	if err != nil {
-		return fmt.Errorf("failed to load content font: %v", err)
+		return nil, fmt.Errorf("failed to load ASCII font: %v", err)
 	}
-	fonts[ContentText] = contentFont
+	return chain, nil
+}
 
-	// Parse the table text and create segments
-	lines := strings.Split(tableText, "\n")
+// parseTableText splits the table text into lines of segments styled by
+// the spans, skipping blank lines
+func parseTableText(tableText string, spans []Span) [][]TextSegment {
 	var segments [][]TextSegment
-
-	for _, line := range lines {
+	for i, line := range strings.Split(tableText, "\n") {
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
-		segments = append(segments, parseLineSegments(line))
+		segments = append(segments, parseLineSegments(line, lineStyles(spans, i, utf8.RuneCountInString(line))))
 	}
+	return segments
+}
 
-	// Calculate image dimensions
-	width, height := calculateImageDimensions(segments, fonts, config)
+// renderPNG draws the table at a scale factor
+func renderPNG(segments [][]TextSegment, chain *fontChain,
+	config PNGConfig, colors palette, factor float64) (*image.RGBA, error) {
 
-	// Create image
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	// Lay out the table and size the image around it
+	size := config.ASCIIFont.Size
+	if size <= 0 {
+		size = DefaultFontSize
+	}
+	faces, err := newFaceChain(chain, size, 72*factor)
+	if err != nil {
+		return nil, err
+	}
+	space := config.spacing().scaled(factor)
+	layout := newGrid(segments, faces.primary(), space)
 
-	// Fill with transparent background
-	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{0, 0, 0, 0}}, image.Point{}, draw.Src)
+	img := image.NewRGBA(image.Rect(0, 0, layout.width+2*space.margin, layout.height+2*space.margin))
 
-	// Render text
-	if err := renderText(img, segments, fonts, config); err != nil {
-		return fmt.Errorf("failed to render text: %v", err)
-	}
+	// Fill with the theme background, transparent by default
+	draw.Draw(img, img.Bounds(), image.NewUniform(colors.background), image.Point{}, draw.Src)
+
+	layout.draw(img, faces, image.Pt(space.margin, space.margin), colors, space)
+	return img, nil
+}
 
-	// Save PNG
-	file, err := os.Create(outputPath)
+// savePNG encodes an image to a file
+func savePNG(img image.Image, path string) error {
+	file, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %v", err)
 	}
-	defer file.Close()
 
 	if err := png.Encode(file, img); err != nil {
+		file.Close()
 		return fmt.Errorf("failed to encode PNG: %v", err)
 	}
-
-	return nil
+	return file.Close()
 }
 
-// loadFont loads a TrueType font from file, with system font resolution
-func loadFont(fontPath string) (*truetype.Font, error) {
-	// Try to resolve system font if it's not a direct path
-	resolvedPath, err := resolveSystemFont(fontPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve font '%s': %v", fontPath, err)
+// loadFont loads a TrueType or OpenType font, with system font resolution,
+// and returns it with the path it was loaded from. A "#N" suffix selects a
+// face of a font collection.
+func loadFont(fontPath string) (*sfnt.Font, string, error) {
+	if fontPath == EmbeddedFont {
+		f, err := loadEmbeddedFont()
+		return f, EmbeddedFont, err
 	}
 
-	fontBytes, err := ioutil.ReadFile(resolvedPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read font file '%s': %v", resolvedPath, err)
-	}
-
-	font, err := truetype.Parse(fontBytes)
+	// Try to resolve system font if it's not a direct path
+	resolved, err := resolveSystemFont(fontPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse font file '%s': %v", resolvedPath, err)
+		return nil, "", fmt.Errorf("failed to resolve font '%s': %v", fontPath, err)
 	}
 
-	return font, nil
+	path, index := splitFaceIndex(resolved)
+	f, err := parseFontFile(path, index)
+	return f, resolved, err
 }
 
-// parseLineSegments parses a line into text segments with their types
-func parseLineSegments(line string) []TextSegment {
-	var segments []TextSegment
-
-	// ASCII characters (box drawing) - expanded to include all table styles
-	asciiChars := "┌┐└┘├┤┬┴┼─│╔╗╚╝╠╣╦╩╬═║"
-
-	// Bold text pattern
-	boldPattern := regexp.MustCompile(`\*\*([^*]+)\*\*`)
-
-	// Find all bold matches
-	boldMatches := boldPattern.FindAllStringSubmatchIndex(line, -1)
-
-	lastIndex := 0
-	for _, match := range boldMatches {
-		start, end := match[0], match[1]
-
-		// Add text before bold
-		if start > lastIndex {
-			beforeText := line[lastIndex:start]
-			segments = append(segments, classifyTextSegments(beforeText, asciiChars)...)
-		}
-
-		// Add bold text as header
-		boldText := line[match[2]:match[3]]
-		segments = append(segments, TextSegment{Text: boldText, Type: HeaderText})
+// boxDrawing lists the box-drawing characters of the table styles, which
+// are drawn as borders
+const boxDrawing = "┌┐└┘├┤┬┴┼─│╔╗╚╝╠╣╦╩╬═║╞╡╤╧╟╢╥╨"
 
-		lastIndex = end
-	}
-
-	// Add remaining text
-	if lastIndex < len(line) {
-		remainingText := line[lastIndex:]
-		segments = append(segments, classifyTextSegments(remainingText, asciiChars)...)
-	}
-
-	return segments
-}
-
-// classifyTextSegments classifies text into ASCII or content segments
-func classifyTextSegments(text string, asciiChars string) []TextSegment {
+// parseLineSegments splits a line into runs of border and content
+// characters that share a style. styles holds the style of each rune, or
+// is nil for an unstyled line.
+func parseLineSegments(line string, styles []*TextStyle) []TextSegment {
 	var segments []TextSegment
-	var currentSegment strings.Builder
-	var currentType TextType = -1
-
-	// Expand ASCII chars to include all common table drawing characters
-	allASCIIChars := asciiChars + "┌┐└┘├┤┬┴┼─│╔╗╚╝╠╣╦╩╬═║"
-
-	for _, char := range text {
-		charType := ContentText
-		if strings.ContainsRune(allASCIIChars, char) {
-			charType = ASCIIText
+	var current strings.Builder
+	var segment TextSegment
+
+	col := 0
+	for _, r := range line {
+		typ := ContentText
+		if strings.ContainsRune(boxDrawing, r) {
+			typ = ASCIIText
 		}
-
-		if currentType == -1 {
-			currentType = charType
+		var style TextStyle
+		if col < len(styles) && styles[col] != nil {
+			style = *styles[col]
 		}
+		col++
 
-		if charType == currentType {
-			currentSegment.WriteRune(char)
-		} else {
-			// Type changed, save current segment
-			if currentSegment.Len() > 0 {
-				segments = append(segments, TextSegment{
-					Text: currentSegment.String(),
-					Type: currentType,
-				})
-			}
-
-			// Start new segment
-			currentSegment.Reset()
-			currentSegment.WriteRune(char)
-			currentType = charType
+		if current.Len() > 0 && (typ != segment.Type || style != segment.Style) {
+			segment.Text = current.String()
+			segments = append(segments, segment)
+			current.Reset()
 		}
+		segment.Type, segment.Style = typ, style
+		current.WriteRune(r)
 	}
-
-	// Add final segment
-	if currentSegment.Len() > 0 {
-		segments = append(segments, TextSegment{
-			Text: currentSegment.String(),
-			Type: currentType,
-		})
+	if current.Len() > 0 {
+		segment.Text = current.String()
+		segments = append(segments, segment)
 	}
 
 	return segments
 }
 
-// renderText renders all text segments to the image
-func renderText(img *image.RGBA, segments [][]TextSegment,
-	fonts map[TextType]*truetype.Font, cfg PNGConfig) error {
-
-	monoFace := newFace(fonts[ASCIIText], cfg.ASCIIFont.Size)
-	lh := lineHeight(monoFace)
-	ascent := monoFace.Metrics().Ascent.Ceil()
-
-	y := 50 + ascent // top padding + ascent
-	x := 50          // left padding is unchanged
+// lineRole is the part of a table a line of text belongs to
+type lineRole int
 
-	for _, lineSegs := range segments {
-		var full strings.Builder
-		for _, s := range lineSegs {
-			full.WriteString(s.Text)
-		}
+const (
+	borderLine lineRole = iota
+	headerLine
+	bodyLine
+	zebraLine
+)
 
-		c := freetype.NewContext()
-		c.SetDPI(72)
-		c.SetFont(fonts[ASCIIText])
-		c.SetFontSize(cfg.ASCIIFont.Size)
-		c.SetClip(img.Bounds())
-		c.SetDst(img)
-		c.SetSrc(image.Black)
-
-		// draw the whole line at baseline y
-		if _, err := c.DrawString(full.String(), freetype.Pt(x, y)); err != nil {
-			return err
+// lineRoles classifies lines as borders (box drawing only), the header (the
+// first row of a table) and body rows, alternating with zebra rows
+func lineRoles(segments [][]TextSegment) []lineRole {
+	roles := make([]lineRole, len(segments))
+	seenHeader := false
+	body := 0
+	for i, lineSegs := range segments {
+		isBorder := true
+		for _, seg := range lineSegs {
+			if seg.Type != ASCIIText && strings.TrimSpace(seg.Text) != "" {
+				isBorder = false
+				break
+			}
 		}
-		y += lh
-	}
-	return nil
-}
-
-func newFace(f *truetype.Font, size float64) font.Face {
-	return truetype.NewFace(f, &truetype.Options{Size: size, DPI: 72})
-}
-
-func lineHeight(face font.Face) int {
-	m := face.Metrics()
-	// Face metrics are 26.6-fixed-point; Ceil() converts to int.
-	return (m.Ascent + m.Descent).Ceil()
-}
 
-// calculateImageDimensions calculates the required image size
-func calculateImageDimensions(segments [][]TextSegment,
-	fonts map[TextType]*truetype.Font, cfg PNGConfig) (int, int) {
-
-	monoFace := newFace(fonts[ASCIIText], cfg.ASCIIFont.Size)
-	lh := lineHeight(monoFace)
-
-	maxWidth := 0
-	for _, lineSegs := range segments {
-		var full strings.Builder
-		for _, s := range lineSegs {
-			full.WriteString(s.Text)
-		}
-		w := font.MeasureString(monoFace, full.String())
-		if int(w>>6) > maxWidth {
-			maxWidth = int(w >> 6)
+		switch {
+		case isBorder:
+			roles[i] = borderLine
+		case !seenHeader && i > 0 && roles[0] == borderLine:
+			roles[i] = headerLine
+			seenHeader = true
+		default:
+			roles[i] = bodyLine
+			if body%2 == 1 {
+				roles[i] = zebraLine
+			}
+			body++
 		}
 	}
-
-	totalHeight := len(segments) * lh
-	// generous padding (unchanged)
-	return maxWidth + 100, totalHeight + 100
+	return roles
 }
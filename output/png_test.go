@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bytes"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testTable is table text with box drawing, wide and missing characters
+const testTable = `┌──────────┬─────────┐
+│ Database │ Status  │
+├──────────┼─────────┤
+│ users-db │ Online  │
+│ café     │ Offline │
+│ 漢字     │ ✓       │
+└──────────┴─────────┘`
+
+// testSpans styles the Offline cell and the café row
+func testSpans() []Span {
+	red := color.RGBA{205, 49, 49, 255}
+	shade := color.RGBA{0, 0, 128, 128}
+	return []Span{
+		{Line: 4, Start: 1, End: 21, Style: TextStyle{Background: &shade}},
+		{Line: 4, Start: 12, End: 21, Style: TextStyle{Color: &red, Bold: true, Italic: true, Underline: true}},
+	}
+}
+
+// renderFile renders the test table and returns the bytes of the image
+func renderFile(t *testing.T, config PNGConfig) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "table.png")
+	if _, err := GeneratePNGSet(testTable, testSpans(), config, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestHermeticDeterministic(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PNGConfig
+	}{
+		{"glyph borders", PNGConfig{Hermetic: true}},
+		{"themed strokes", PNGConfig{Hermetic: true, Theme: &Theme{Name: "dark"}, PaddingX: intPtr(6), PaddingY: intPtr(4)}},
+		{"scaled", PNGConfig{Hermetic: true, Theme: &Theme{Name: "light"}, Scale: 2, ASCIIFont: FontConfig{Size: 14}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := renderFile(t, tt.config)
+			if second := renderFile(t, tt.config); !bytes.Equal(first, second) {
+				t.Error("rendering twice gave different images")
+			}
+		})
+	}
+}
+
+func TestHermeticIgnoresConfiguredFonts(t *testing.T) {
+	want := renderFile(t, PNGConfig{Hermetic: true})
+
+	fonts := []FontConfig{
+		{Path: "/no/such/font.ttf"},
+		{Path: "monospace", Fallback: []string{"Qzxv Unfindable Sans"}},
+		{Path: writeEmbeddedFont(t, "Mono.ttf")},
+	}
+	for _, font := range fonts {
+		config := PNGConfig{Hermetic: true, ASCIIFont: font, TitleFont: font, ContentFont: font}
+		if got := renderFile(t, config); !bytes.Equal(got, want) {
+			t.Errorf("hermetic image with ascii_font %+v differs from the default", font)
+		}
+	}
+}
+
+func TestHermeticMissing(t *testing.T) {
+	result, err := GeneratePNGSet(testTable, nil, PNGConfig{Hermetic: true}, filepath.Join(t.TempDir(), "table.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(result.Missing); got != "漢字✓" {
+		t.Errorf("missing = %q, want %q", got, "漢字✓")
+	}
+}
+
+func TestGeneratePNGScales(t *testing.T) {
+	dir := t.TempDir()
+	config := PNGConfig{Hermetic: true, Scales: []float64{1, 2, 2, 1.5}}
+	result, err := GeneratePNGSet(testTable, nil, config, filepath.Join(dir, "table.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"table.png", "table@2x.png", "table@1.5x.png"}
+	var got []string
+	for _, path := range result.Paths {
+		got = append(got, filepath.Base(path))
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+}
+
+func TestScaledPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		scale float64
+		want  string
+	}{
+		{"table.png", 0, "table.png"},
+		{"table.png", 1, "table.png"},
+		{"table.png", 2, "table@2x.png"},
+		{"out/table.png", 1.5, "out/table@1.5x.png"},
+		{"table", 3, "table@3x"},
+	}
+	for _, tt := range tests {
+		if got := ScaledPath(tt.path, tt.scale); got != tt.want {
+			t.Errorf("ScaledPath(%q, %v) = %q, want %q", tt.path, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestParseTableText(t *testing.T) {
+	bold := TextStyle{Bold: true}
+	spans := []Span{{Line: 3, Start: 1, End: 4, Style: bold}}
+
+	got := parseTableText("┌─┐\n\n  \n│ab│c", spans)
+	want := [][]TextSegment{
+		{{Text: "┌─┐", Type: ASCIIText}},
+		{
+			{Text: "│", Type: ASCIIText},
+			{Text: "ab", Type: ContentText, Style: bold},
+			{Text: "│", Type: ASCIIText, Style: bold},
+			{Text: "c", Type: ContentText},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTableText = %+v, want %+v", got, want)
+	}
+}
+
+func TestLineStyles(t *testing.T) {
+	first, second := TextStyle{Bold: true}, TextStyle{Italic: true}
+	spans := []Span{
+		{Line: 1, Start: -2, End: 3, Style: first},
+		{Line: 1, Start: 2, End: 9, Style: second},
+		{Line: 2, Start: 0, End: 1, Style: first},
+	}
+
+	if got := lineStyles(spans, 0, 5); got != nil {
+		t.Errorf("line without spans = %v, want nil", got)
+	}
+
+	got := lineStyles(spans, 1, 5)
+	want := []*TextStyle{&first, &first, &second, &second, &second}
+	if len(got) != len(want) {
+		t.Fatalf("got %d styles, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] == nil || *got[i] != *want[i] {
+			t.Errorf("style %d = %v, want %v", i, got[i], *want[i])
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package output
+
+import "image/color"
+
+// TextStyle is the color and emphasis of a run of text. Nil colors use the
+// image defaults.
+type TextStyle struct {
+	Color      *color.RGBA
+	Background *color.RGBA
+	Bold       bool
+	Italic     bool
+	Underline  bool
+}
+
+// Span styles a run of characters on one line of the table text. Start and
+// End count runes from the beginning of the line; End is exclusive.
+type Span struct {
+	Line  int
+	Start int
+	End   int
+	Style TextStyle
+}
+
+// lineStyles returns the style of each rune of a line, nil where no span
+// applies. Later spans override earlier ones.
+func lineStyles(spans []Span, line, length int) []*TextStyle {
+	var styles []*TextStyle
+	for i := range spans {
+		span := &spans[i]
+		if span.Line != line {
+			continue
+		}
+		if styles == nil {
+			styles = make([]*TextStyle, length)
+		}
+		for col := max(span.Start, 0); col < min(span.End, length); col++ {
+			styles[col] = &span.Style
+		}
+	}
+	return styles
+}
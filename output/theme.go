@@ -0,0 +1,161 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// Theme sets the colors of a PNG image. In JSON it is written either as the
+// name of a preset ("dark") or as an object whose colors override the preset
+// given in Name: {"name": "dark", "border": "#888"}. Colors accept names,
+// hex values and rgb()/rgba().
+type Theme struct {
+	Name             string `json:"name,omitempty"`
+	Background       string `json:"background,omitempty"`
+	Text             string `json:"text,omitempty"`
+	Border           string `json:"border,omitempty"`
+	HeaderText       string `json:"header_text,omitempty"`
+	HeaderBackground string `json:"header_background,omitempty"`
+	// Zebra fills every other body row
+	Zebra string `json:"zebra,omitempty"`
+}
+
+// themePresets are the built-in themes. Without a theme images keep a
+// transparent background and black text.
+var themePresets = map[string]Theme{
+	"light": {
+		Background:       "#ffffff",
+		Text:             "#1f2328",
+		Border:           "#8c959f",
+		HeaderText:       "#1f2328",
+		HeaderBackground: "#eaeef2",
+		Zebra:            "#f6f8fa",
+	},
+	"dark": {
+		Background:       "#1e1e1e",
+		Text:             "#d4d4d4",
+		Border:           "#6e7681",
+		HeaderText:       "#ffffff",
+		HeaderBackground: "#2d333b",
+		Zebra:            "#252526",
+	},
+	"high-contrast": {
+		Background:       "#000000",
+		Text:             "#ffffff",
+		Border:           "#ffffff",
+		HeaderText:       "#000000",
+		HeaderBackground: "#ffd700",
+		Zebra:            "#1a1a1a",
+	},
+}
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(themePresets))
+	for name := range themePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnmarshalJSON accepts a preset name or an object
+func (t *Theme) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		type themeObject Theme
+		var obj themeObject
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("invalid theme: %v", err)
+		}
+		*t = Theme(obj)
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("invalid theme: %v", err)
+	}
+	*t = Theme{Name: name}
+	return nil
+}
+
+// palette holds the resolved colors of a theme. Nil fills are not drawn.
+type palette struct {
+	background       color.RGBA
+	text             color.RGBA
+	border           color.RGBA
+	headerText       color.RGBA
+	headerBackground *color.RGBA
+	zebra            *color.RGBA
+}
+
+// defaultPalette matches the images generated without a theme
+var defaultPalette = palette{
+	text:       color.RGBA{0, 0, 0, 255},
+	border:     color.RGBA{0, 0, 0, 255},
+	headerText: color.RGBA{0, 0, 0, 255},
+}
+
+// resolve merges a theme over its preset and parses the colors
+func (t *Theme) resolve() (palette, error) {
+	if t == nil {
+		return defaultPalette, nil
+	}
+
+	theme := *t
+	if theme.Name != "" {
+		preset, ok := themePresets[strings.ToLower(theme.Name)]
+		if !ok {
+			return palette{}, fmt.Errorf("unknown theme %q. Available themes: %v", theme.Name, ThemeNames())
+		}
+		fill := func(value *string, def string) {
+			if *value == "" {
+				*value = def
+			}
+		}
+		fill(&theme.Background, preset.Background)
+		fill(&theme.Text, preset.Text)
+		fill(&theme.Border, preset.Border)
+		fill(&theme.HeaderText, preset.HeaderText)
+		fill(&theme.HeaderBackground, preset.HeaderBackground)
+		fill(&theme.Zebra, preset.Zebra)
+	}
+
+	p := defaultPalette
+	var err error
+	parse := func(field, value string, dst *color.RGBA) {
+		if err != nil || value == "" {
+			return
+		}
+		c, parseErr := ParseColor(value)
+		if parseErr != nil {
+			err = fmt.Errorf("theme %s: %v", field, parseErr)
+			return
+		}
+		*dst = c
+	}
+	parseFill := func(field, value string) *color.RGBA {
+		if value == "" {
+			return nil
+		}
+		var c color.RGBA
+		parse(field, value, &c)
+		return &c
+	}
+
+	parse("background", theme.Background, &p.background)
+	parse("text", theme.Text, &p.text)
+	// Borders and header text follow the body text unless set
+	p.border, p.headerText = p.text, p.text
+	parse("border", theme.Border, &p.border)
+	parse("header_text", theme.HeaderText, &p.headerText)
+	p.headerBackground = parseFill("header_background", theme.HeaderBackground)
+	p.zebra = parseFill("zebra", theme.Zebra)
+
+	return p, err
+}
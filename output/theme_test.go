@@ -0,0 +1,146 @@
+package output
+
+import (
+	"encoding/json"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func rgba(r, g, b uint8) *color.RGBA {
+	return &color.RGBA{r, g, b, 255}
+}
+
+func TestThemeResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		theme string // JSON value of the theme field, empty for no theme
+		want  palette
+	}{
+		{
+			name: "no theme",
+			want: defaultPalette,
+		},
+		{
+			name:  "preset",
+			theme: `"dark"`,
+			want: palette{
+				background:       *rgba(0x1e, 0x1e, 0x1e),
+				text:             *rgba(0xd4, 0xd4, 0xd4),
+				border:           *rgba(0x6e, 0x76, 0x81),
+				headerText:       *rgba(0xff, 0xff, 0xff),
+				headerBackground: rgba(0x2d, 0x33, 0x3b),
+				zebra:            rgba(0x25, 0x25, 0x26),
+			},
+		},
+		{
+			name:  "preset name is case-insensitive",
+			theme: `"High-Contrast"`,
+			want: palette{
+				background:       *rgba(0, 0, 0),
+				text:             *rgba(0xff, 0xff, 0xff),
+				border:           *rgba(0xff, 0xff, 0xff),
+				headerText:       *rgba(0, 0, 0),
+				headerBackground: rgba(0xff, 0xd7, 0),
+				zebra:            rgba(0x1a, 0x1a, 0x1a),
+			},
+		},
+		{
+			name:  "overrides",
+			theme: `{"name": "light", "border": "red", "zebra": "rgba(0, 0, 255, 0.5)"}`,
+			want: palette{
+				background:       *rgba(0xff, 0xff, 0xff),
+				text:             *rgba(0x1f, 0x23, 0x28),
+				border:           color.RGBA{205, 49, 49, 255},
+				headerText:       *rgba(0x1f, 0x23, 0x28),
+				headerBackground: rgba(0xea, 0xee, 0xf2),
+				zebra:            &color.RGBA{0, 0, 128, 128},
+			},
+		},
+		{
+			name:  "borders and header text follow the text",
+			theme: `{"text": "#333", "background": "transparent"}`,
+			want: palette{
+				text:       *rgba(0x33, 0x33, 0x33),
+				border:     *rgba(0x33, 0x33, 0x33),
+				headerText: *rgba(0x33, 0x33, 0x33),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var theme *Theme
+			if tt.theme != "" {
+				theme = new(Theme)
+				if err := json.Unmarshal([]byte(tt.theme), theme); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := theme.resolve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThemeResolveErrors(t *testing.T) {
+	tests := []struct {
+		theme Theme
+		want  string
+	}{
+		{Theme{Name: "solarized"}, `unknown theme "solarized". Available themes: [dark high-contrast light]`},
+		{Theme{Background: "#12"}, `theme background: invalid color "#12"`},
+		{Theme{Name: "dark", Zebra: "stripes"}, `theme zebra: invalid color "stripes"`},
+		{Theme{Text: "#fff", HeaderText: "rgb(1, 2)"}, `theme header_text: invalid color "rgb(1, 2)"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			_, err := tt.theme.resolve()
+			if err == nil {
+				t.Fatal("resolve succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestThemeUnmarshal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Theme
+		wantErr string
+	}{
+		{`"dark"`, Theme{Name: "dark"}, ""},
+		{` {"name": "light", "header_background": "#eee"}`, Theme{Name: "light", HeaderBackground: "#eee"}, ""},
+		{`42`, Theme{}, "invalid theme"},
+		{`{"border": 1}`, Theme{}, "invalid theme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Theme
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal(%s) error = %v, want %q", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
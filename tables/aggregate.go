@@ -0,0 +1,84 @@
+package tables
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Aggregations available for subtotals and pivots
+const (
+	AggregateSum   = "sum"
+	AggregateCount = "count"
+	AggregateAvg   = "avg"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	AggregateFirst = "first"
+)
+
+// parseAggregate validates an aggregation name, defaulting to sum
+func parseAggregate(name string) (string, error) {
+	switch kind := strings.ToLower(strings.TrimSpace(name)); kind {
+	case "":
+		return AggregateSum, nil
+	case "average", "mean":
+		return AggregateAvg, nil
+	case AggregateSum, AggregateCount, AggregateAvg, AggregateMin, AggregateMax, AggregateFirst:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown aggregation %q. Available aggregations: [sum count avg min max first]", name)
+	}
+}
+
+// aggregate combines cell texts. Numeric aggregations use the values that
+// parse as numbers and return "" when there are none; count counts the
+// non-empty cells and first returns the first non-empty cell.
+func aggregate(kind string, texts []string) string {
+	var values []float64
+	count := 0
+	first := ""
+	for _, text := range texts {
+		if strings.TrimSpace(cleanText(text)) == "" {
+			continue
+		}
+		if count == 0 {
+			first = text
+		}
+		count++
+		if v, ok := parseNumber(text); ok {
+			values = append(values, v)
+		}
+	}
+
+	switch kind {
+	case AggregateCount:
+		return strconv.Itoa(count)
+	case AggregateFirst:
+		return first
+	}
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	result := values[0]
+	switch kind {
+	case AggregateSum, AggregateAvg:
+		result = 0
+		for _, v := range values {
+			result += v
+		}
+		if kind == AggregateAvg {
+			result /= float64(len(values))
+		}
+	case AggregateMin:
+		for _, v := range values[1:] {
+			result = min(result, v)
+		}
+	case AggregateMax:
+		for _, v := range values[1:] {
+			result = max(result, v)
+		}
+	}
+	return strconv.FormatFloat(result, 'f', -1, 64)
+}
@@ -0,0 +1,299 @@
+package tables
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTableType is the style used by tables built without an explicit type
+const DefaultTableType = "single-line-full"
+
+// Table builds a TableConfig programmatically
+type Table struct {
+	config     TableConfig
+	timeLayout string
+}
+
+// CellValue wraps a row value with per-cell options
+type CellValue struct {
+	Value  any
+	Bold   bool
+	Format string
+	Align  AlignmentType
+}
+
+// CellOption configures a CellValue
+type CellOption func(*CellValue)
+
+// Bold renders the cell in bold
+func Bold() CellOption {
+	return func(c *CellValue) {
+		c.Bold = true
+	}
+}
+
+// WithFormat formats the cell with a printf-style format, or a time layout for time.Time values
+func WithFormat(format string) CellOption {
+	return func(c *CellValue) {
+		c.Format = format
+	}
+}
+
+// WithAlign overrides the row and column alignment for the cell
+func WithAlign(align AlignmentType) CellOption {
+	return func(c *CellValue) {
+		c.Align = align
+	}
+}
+
+// Value wraps a value with per-cell options for use with AddRow
+func Value(v any, opts ...CellOption) CellValue {
+	cell := CellValue{Value: v}
+	for _, opt := range opts {
+		opt(&cell)
+	}
+	return cell
+}
+
+// NewTable returns an empty table using the default style
+func NewTable() *Table {
+	return &Table{
+		config:     TableConfig{Type: DefaultTableType},
+		timeLayout: time.RFC3339,
+	}
+}
+
+// Title sets the table name
+func (t *Table) Title(name string) *Table {
+	t.config.Name = name
+	return t
+}
+
+// Style sets the table type used to look up the renderer
+func (t *Table) Style(tableType string) *Table {
+	t.config.Type = tableType
+	return t
+}
+
+// Headers sets the column headers
+func (t *Table) Headers(headers ...string) *Table {
+	t.config.Headers = headers
+	return t
+}
+
+// AddRow appends a row, formatting each value according to its type
+func (t *Table) AddRow(values ...any) *Table {
+	row := Row{Cells: make([]Cell, len(values))}
+	for i, v := range values {
+		row.Cells[i] = t.formatCell(v)
+	}
+	t.config.Rows = append(t.config.Rows, row)
+	return t
+}
+
+// AlignRow overrides the column alignment for every cell of a row
+func (t *Table) AlignRow(row int, align AlignmentType) *Table {
+	if row < len(t.config.Rows) {
+		t.config.Rows[row].Align = string(align)
+	}
+	return t
+}
+
+// Align sets the alignment of a column
+func (t *Table) Align(col int, align AlignmentType) *Table {
+	for len(t.config.Alignment) <= col {
+		t.config.Alignment = append(t.config.Alignment, string(AlignLeft))
+	}
+	t.config.Alignment[col] = string(align)
+	return t
+}
+
+// HeaderAlign sets the alignment of a column header, overriding the column alignment
+func (t *Table) HeaderAlign(col int, align AlignmentType) *Table {
+	for len(t.config.HeaderAlignment) <= col {
+		t.config.HeaderAlignment = append(t.config.HeaderAlignment, "")
+	}
+	t.config.HeaderAlignment[col] = string(align)
+	return t
+}
+
+// Format sets the number format of a column (see TableConfig.Format)
+func (t *Table) Format(col int, spec string) *Table {
+	for len(t.config.Format) <= col {
+		t.config.Format = append(t.config.Format, "")
+	}
+	t.config.Format[col] = spec
+	return t
+}
+
+// Compute adds a column computed from an expression over the other
+// columns, e.g. "Used / Total * 100"
+func (t *Table) Compute(name, expr string) *Table {
+	t.config.ComputedColumns = append(t.config.ComputedColumns, ComputedColumn{Name: name, Expr: expr})
+	return t
+}
+
+// ComputeTemplate adds a column rendered from a text/template, e.g.
+// "{{.Region}} ({{.Sectors}})"
+func (t *Table) ComputeTemplate(name, text string) *Table {
+	t.config.ComputedColumns = append(t.config.ComputedColumns, ComputedColumn{Name: name, Template: text})
+	return t
+}
+
+// Filter keeps the rows matching an expression, e.g. `Status != "Offline"`
+func (t *Table) Filter(expr string) *Table {
+	t.config.Filter = expr
+	return t
+}
+
+// Rule styles cells of the rows matching an expression. An empty column
+// styles the whole row.
+func (t *Table) Rule(when, column string, style CellStyle) *Table {
+	t.config.Rules = append(t.config.Rules, Rule{When: when, Column: column, CellStyle: style})
+	return t
+}
+
+// Sort orders rows by one or more columns, e.g. "Connections:desc,Database"
+func (t *Table) Sort(spec string) *Table {
+	t.config.Sort = spec
+	return t
+}
+
+// GroupBy clusters rows by a column under a section row per value, adding a
+// subtotal row per group when subtotal columns are given (e.g. "Connections"
+// or "Response Time:avg")
+func (t *Table) GroupBy(column string, subtotals ...string) *Table {
+	t.config.GroupBy = column
+	t.config.Subtotals = subtotals
+	return t
+}
+
+// Pivot turns long-format rows into a cross-tab with a row per value of the
+// rows column and a column per value of the columns column. The aggregate
+// defaults to sum.
+func (t *Table) Pivot(rows, columns, values, aggregate string) *Table {
+	t.config.Pivot = &PivotSpec{Rows: rows, Columns: columns, Values: values, Aggregate: aggregate}
+	return t
+}
+
+// Transpose renders each record vertically as field/value rows
+func (t *Table) Transpose() *Table {
+	t.config.Transpose = true
+	return t
+}
+
+// EmptyText sets the placeholder shown when the table has no rows
+func (t *Table) EmptyText(text string) *Table {
+	t.config.EmptyText = text
+	return t
+}
+
+// TimeFormat sets the layout used for time.Time values (defaults to RFC 3339)
+func (t *Table) TimeFormat(layout string) *Table {
+	t.timeLayout = layout
+	return t
+}
+
+// Config returns the table configuration built so far
+func (t *Table) Config() TableConfig {
+	return t.config
+}
+
+// Render writes the table using the renderer registered for its type
+func (t *Table) Render(w io.Writer) error {
+	renderer, err := GetRenderer(t.config.Type)
+	if err != nil {
+		return err
+	}
+	return renderer.Render(w, t.config)
+}
+
+// RenderWith writes the table using the given renderer
+func (t *Table) RenderWith(w io.Writer, renderer TableRenderer) error {
+	return renderer.Render(w, t.config)
+}
+
+// String renders the table, returning an empty string if rendering fails
+func (t *Table) String() string {
+	var result strings.Builder
+	if err := t.Render(&result); err != nil {
+		return ""
+	}
+	return result.String()
+}
+
+// formatCell converts a row value, optionally wrapped in a CellValue, to a cell
+func (t *Table) formatCell(v any) Cell {
+	cell, ok := v.(CellValue)
+	if !ok {
+		return Cell{Text: formatValue(v, t.timeLayout)}
+	}
+
+	var text string
+	switch {
+	case cell.Format == "":
+		text = formatValue(cell.Value, t.timeLayout)
+	case isTime(cell.Value):
+		text = formatValue(cell.Value, cell.Format)
+	default:
+		text = fmt.Sprintf(cell.Format, cell.Value)
+	}
+
+	if cell.Bold && text != "" {
+		text = "**" + text + "**"
+	}
+	return Cell{Text: text, Align: string(cell.Align)}
+}
+
+func isTime(v any) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+// formatValue converts a Go value to cell text
+func formatValue(v any, timeLayout string) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format(timeLayout)
+	case time.Duration:
+		return value.String()
+	case fmt.Stringer:
+		return value.String()
+	case error:
+		return value.Error()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return ""
+		}
+		return formatValue(rv.Elem().Interface(), timeLayout)
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	case reflect.String:
+		return rv.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,129 @@
+package tables
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Cell is a single table cell. In JSON it is written either as a plain
+// value ("text", 42) or as an object carrying per-cell options:
+// {"text": "42", "align": "right", "style": {"color": "red"}}.
+type Cell struct {
+	Text  string     `json:"text"`
+	Align string     `json:"align,omitempty"`
+	Style *CellStyle `json:"style,omitempty"`
+}
+
+// Row is a table row. In JSON it is written either as an array of cells or
+// as an object carrying per-row options: {"cells": [...], "align": "right"}.
+// A row with a Section title is drawn as a section row spanning all
+// columns: {"section": "Replicas"}.
+type Row struct {
+	Cells   []Cell `json:"cells"`
+	Align   string `json:"align,omitempty"`
+	Section string `json:"section,omitempty"`
+}
+
+// TextRow builds a row from plain cell text
+func TextRow(values ...string) Row {
+	cells := make([]Cell, len(values))
+	for i, value := range values {
+		cells[i] = Cell{Text: value}
+	}
+	return Row{Cells: cells}
+}
+
+// TextRows builds rows from plain cell text
+func TextRows(rows [][]string) []Row {
+	result := make([]Row, len(rows))
+	for i, row := range rows {
+		result[i] = TextRow(row...)
+	}
+	return result
+}
+
+// Texts returns the text of each cell in the row
+func (r Row) Texts() []string {
+	texts := make([]string, len(r.Cells))
+	for i, cell := range r.Cells {
+		texts[i] = cell.Text
+	}
+	return texts
+}
+
+// Text returns the text of a cell, or "" when the row is shorter
+func (r Row) Text(col int) string {
+	if col < len(r.Cells) {
+		return r.Cells[col].Text
+	}
+	return ""
+}
+
+// withTexts returns a copy of the row with new cell text, keeping the cell options
+func (r Row) withTexts(texts []string) Row {
+	cells := make([]Cell, len(texts))
+	for i, text := range texts {
+		if i < len(r.Cells) {
+			cells[i] = r.Cells[i]
+		}
+		cells[i].Text = text
+	}
+	return Row{Cells: cells, Align: r.Align}
+}
+
+// UnmarshalJSON accepts a plain value or an object with options
+func (c *Cell) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		type cellObject Cell
+		var obj cellObject
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("invalid cell: %v", err)
+		}
+		*c = Cell(obj)
+		return nil
+	}
+
+	*c = Cell{Text: jsonCellText(data)}
+	return nil
+}
+
+// MarshalJSON writes plain cells as strings and cells with options as objects
+func (c Cell) MarshalJSON() ([]byte, error) {
+	if c.Align == "" && c.Style == nil {
+		return json.Marshal(c.Text)
+	}
+	type cellObject Cell
+	return json.Marshal(cellObject(c))
+}
+
+// UnmarshalJSON accepts an array of cells or an object with options
+func (r *Row) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		type rowObject Row
+		var obj rowObject
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("invalid row: %v", err)
+		}
+		*r = Row(obj)
+		return nil
+	}
+
+	var cells []Cell
+	if err := json.Unmarshal(data, &cells); err != nil {
+		return fmt.Errorf("invalid row: %v", err)
+	}
+	*r = Row{Cells: cells}
+	return nil
+}
+
+// MarshalJSON writes plain rows as arrays and rows with options as objects
+func (r Row) MarshalJSON() ([]byte, error) {
+	if r.Align == "" && r.Section == "" {
+		return json.Marshal(r.Cells)
+	}
+	type rowObject Row
+	return json.Marshal(rowObject(r))
+}
@@ -0,0 +1,175 @@
+package tables
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ColumnSpec selects a source column by header name and optionally renames
+// it and sets its alignment and format. In JSON it is written either as a
+// string ("Connections" or "Connections=Conns") or as an object.
+type ColumnSpec struct {
+	Name        string `json:"name"`
+	Header      string `json:"header,omitempty"`
+	Align       string `json:"align,omitempty"`
+	HeaderAlign string `json:"header_align,omitempty"`
+	Format      string `json:"format,omitempty"`
+}
+
+// parseColumnSpec parses the "Name" or "Name=Header" shorthand
+func parseColumnSpec(text string) ColumnSpec {
+	name, header, _ := strings.Cut(text, "=")
+	return ColumnSpec{Name: strings.TrimSpace(name), Header: strings.TrimSpace(header)}
+}
+
+// ParseColumns parses a comma-separated column list such as
+// "Database,Connections=Conns,Status"
+func ParseColumns(list string) ([]ColumnSpec, error) {
+	var specs []ColumnSpec
+	for _, field := range strings.Split(list, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		spec := parseColumnSpec(field)
+		if spec.Name == "" {
+			return nil, fmt.Errorf("columns: missing column name in %q", field)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// OverrideColumns replaces configured columns with a new selection, such as
+// one parsed from the command line. Selected columns the configuration also
+// lists keep its alignment, format and, unless renamed, header.
+func OverrideColumns(configured, selected []ColumnSpec) []ColumnSpec {
+	merged := make([]ColumnSpec, len(selected))
+	for i, spec := range selected {
+		for _, existing := range configured {
+			if strings.EqualFold(existing.Name, spec.Name) {
+				if spec.Header == "" {
+					spec.Header = existing.Header
+				}
+				spec.Align, spec.HeaderAlign, spec.Format = existing.Align, existing.HeaderAlign, existing.Format
+				break
+			}
+		}
+		merged[i] = spec
+	}
+	return merged
+}
+
+// UnmarshalJSON accepts the string shorthand or an object
+func (c *ColumnSpec) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		type columnObject ColumnSpec
+		var obj columnObject
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("invalid column: %v", err)
+		}
+		*c = ColumnSpec(obj)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid column: %v", err)
+	}
+	*c = parseColumnSpec(text)
+	return nil
+}
+
+// projection maps output columns to source columns together with the
+// header, alignment and format each output column ends up with
+type projection struct {
+	sources         []int
+	headers         []string
+	alignment       []string
+	headerAlignment []string
+	formats         []string
+	identity        bool
+}
+
+// newProjection resolves the selected columns of a configuration. Index
+// based Alignment, HeaderAlignment and Format entries belong to the source
+// column and follow it when columns are reordered; options set on a
+// ColumnSpec take precedence.
+func newProjection(config TableConfig) (*projection, error) {
+	specs := config.Columns
+	identity := len(specs) == 0
+	if identity {
+		for _, header := range config.Headers {
+			specs = append(specs, ColumnSpec{Name: header})
+		}
+	}
+
+	at := func(values []string, i int) string {
+		if i < len(values) {
+			return values[i]
+		}
+		return ""
+	}
+
+	p := &projection{identity: identity}
+	for i, spec := range specs {
+		source := i
+		if !identity {
+			var err error
+			if source, err = columnIndex(config.Headers, spec.Name); err != nil {
+				return nil, fmt.Errorf("columns: %v", err)
+			}
+		}
+
+		header := spec.Header
+		if header == "" {
+			header = config.Headers[source]
+		}
+		align := spec.Align
+		if align == "" {
+			align = at(config.Alignment, source)
+		}
+		headerAlign := spec.HeaderAlign
+		if headerAlign == "" {
+			headerAlign = at(config.HeaderAlignment, source)
+		}
+		format := spec.Format
+		if format == "" {
+			format = at(config.Format, source)
+		}
+
+		p.sources = append(p.sources, source)
+		p.headers = append(p.headers, header)
+		p.alignment = append(p.alignment, align)
+		p.headerAlignment = append(p.headerAlignment, headerAlign)
+		p.formats = append(p.formats, format)
+	}
+
+	return p, nil
+}
+
+// row reorders the cells of a row, keeping per-cell options with their cell
+func (p *projection) row(row Row) Row {
+	if p.identity {
+		return row
+	}
+	cells := make([]Cell, len(p.sources))
+	for i, source := range p.sources {
+		if source < len(row.Cells) {
+			cells[i] = row.Cells[source]
+		}
+	}
+	return Row{Cells: cells, Align: row.Align}
+}
+
+// apply replaces the column definitions of a configuration with the projected ones
+func (p *projection) apply(config TableConfig) TableConfig {
+	config.Headers = p.headers
+	config.Alignment = p.alignment
+	config.HeaderAlignment = p.headerAlignment
+	config.Format = nil
+	config.Columns = nil
+	return config
+}
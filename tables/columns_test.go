@@ -0,0 +1,179 @@
+package tables
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var columnsConfig = TableConfig{
+	Headers:   []string{"Database", "Status", "Connections", "Load"},
+	Alignment: []string{"left", "left", "right", "left"},
+	Format:    []string{"", "", "", "percent"},
+	Rows: TextRows([][]string{
+		{"Primary", "Online", "45", "0.75"},
+		{"Replica", "Offline", "3", "0.1"},
+	}),
+}
+
+func TestColumnsRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string // JSON value of the columns field
+		want    []string
+		absent  []string
+	}{
+		{
+			name:    "select and reorder",
+			columns: `["Load", "Database"]`,
+			want:    []string{"│ Load │ Database │", "│ 75%  │ Primary  │"},
+			absent:  []string{"Status", "Online"},
+		},
+		{
+			name:    "rename",
+			columns: `["Database=DB", "Connections = Conns"]`,
+			want:    []string{"│ DB      │ Conns │", "│ Primary │    45 │"},
+		},
+		{
+			name:    "case-insensitive names",
+			columns: `["connections", "DATABASE"]`,
+			want:    []string{"│ Connections │ Database │", "│          45 │ Primary  │"},
+		},
+		{
+			name:    "object options override the source column",
+			columns: `[{"name": "Connections", "header": "Conns", "align": "left"}, {"name": "Load", "format": "fixed:1"}]`,
+			want:    []string{"│ Conns │ Load │", "│ 45    │ 0.8  │"},
+		},
+		{
+			name:    "column shown twice",
+			columns: `["Database", "Database=Name"]`,
+			want:    []string{"│ Database │ Name    │", "│ Primary  │ Primary │"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := columnsConfig
+			if err := json.Unmarshal([]byte(tt.columns), &config.Columns); err != nil {
+				t.Fatal(err)
+			}
+			out := renderTable(t, config)
+			for _, line := range tt.want {
+				if !strings.Contains(out, line) {
+					t.Errorf("output has no line containing %q:\n%s", line, out)
+				}
+			}
+			for _, text := range tt.absent {
+				if strings.Contains(out, text) {
+					t.Errorf("output contains %q:\n%s", text, out)
+				}
+			}
+		})
+	}
+}
+
+func TestColumnsFilterHiddenColumn(t *testing.T) {
+	config := columnsConfig
+	config.Columns = []ColumnSpec{{Name: "Database"}}
+	config.Filter = `Status == "Offline"`
+	config.Sort = "Connections"
+
+	out := renderTable(t, config)
+	if !strings.Contains(out, "│ Replica  │") || strings.Contains(out, "Primary") {
+		t.Errorf("filter on a hidden column not applied:\n%s", out)
+	}
+}
+
+func TestColumnsUnknown(t *testing.T) {
+	config := columnsConfig
+	config.Columns = []ColumnSpec{{Name: "Database"}, {Name: "Uptime"}}
+
+	renderer, err := GetRenderer("single-line-full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = RenderString(renderer, config)
+	if err == nil {
+		t.Fatal("render succeeded, want error")
+	}
+	if want := `columns: unknown column "Uptime"`; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []ColumnSpec
+		wantErr string
+	}{
+		{"Database", []ColumnSpec{{Name: "Database"}}, ""},
+		{" Database , Connections=Conns ,", []ColumnSpec{{Name: "Database"}, {Name: "Connections", Header: "Conns"}}, ""},
+		{"Response Time=Latency", []ColumnSpec{{Name: "Response Time", Header: "Latency"}}, ""},
+		{"Database,=Name", nil, `missing column name in "=Name"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			got, err := ParseColumns(tt.list)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseColumns(%q) error = %v, want %q", tt.list, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseColumns(%q) = %+v, want %+v", tt.list, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrideColumns(t *testing.T) {
+	configured := []ColumnSpec{
+		{Name: "Database", Header: "DB", Align: "center"},
+		{Name: "Load", Header: "Load %", Align: "right", HeaderAlign: "left", Format: "percent"},
+		{Name: "Status"},
+	}
+
+	tests := []struct {
+		name     string
+		selected string
+		want     []ColumnSpec
+	}{
+		{
+			name:     "keeps options and headers",
+			selected: "load,Database",
+			want: []ColumnSpec{
+				{Name: "load", Header: "Load %", Align: "right", HeaderAlign: "left", Format: "percent"},
+				{Name: "Database", Header: "DB", Align: "center"},
+			},
+		},
+		{
+			name:     "rename wins",
+			selected: "Database=Name",
+			want:     []ColumnSpec{{Name: "Database", Header: "Name", Align: "center"}},
+		},
+		{
+			name:     "columns the config does not list",
+			selected: "Connections,Status",
+			want:     []ColumnSpec{{Name: "Connections"}, {Name: "Status"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := ParseColumns(tt.selected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := OverrideColumns(configured, selected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OverrideColumns = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// ComputedColumn derives a column from the other columns of each row, using
+// either an expression ("Used / Total * 100") or a text/template
+// ("{{.Region}} ({{.Sectors}})"). Computed columns are added after the
+// source columns and can be selected, filtered, sorted and formatted like
+// them.
+type ComputedColumn struct {
+	Name     string `json:"name"`
+	Expr     string `json:"expr,omitempty"`
+	Template string `json:"template,omitempty"`
+	Align    string `json:"align,omitempty"`
+	Format   string `json:"format,omitempty"`
+}
+
+// computedColumn is a compiled ComputedColumn
+type computedColumn struct {
+	name    string
+	expr    exprNode
+	tmpl    *template.Template
+	headers []string // columns visible to the template
+}
+
+// templateFuncs lists the functions available in templates, in addition to
+// the text/template built-ins
+var templateFuncs = template.FuncMap{
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+	"replace": strings.ReplaceAll,
+	"format":  func(spec, text string) (string, error) { return formatText(text, spec) },
+}
+
+// withComputedColumns compiles the computed columns of a configuration and
+// returns the configuration with their headers, alignment and formats
+// appended. Each column may refer to the columns computed before it.
+func withComputedColumns(config TableConfig) (TableConfig, []computedColumn, error) {
+	if len(config.ComputedColumns) == 0 {
+		return config, nil, nil
+	}
+
+	width := len(config.Headers)
+	pad := func(values []string) []string {
+		padded := make([]string, width, width+len(config.ComputedColumns))
+		copy(padded, values)
+		return padded
+	}
+
+	extended := config
+	extended.Headers = append([]string(nil), config.Headers...)
+	extended.Alignment = pad(config.Alignment)
+	extended.HeaderAlignment = pad(config.HeaderAlignment)
+	extended.Format = pad(config.Format)
+
+	var columns []computedColumn
+	for _, spec := range config.ComputedColumns {
+		if strings.TrimSpace(spec.Name) == "" {
+			return config, nil, fmt.Errorf("computed column: missing name")
+		}
+
+		column := computedColumn{name: spec.Name, headers: extended.Headers}
+		var err error
+		switch {
+		case spec.Expr != "" && spec.Template != "":
+			return config, nil, fmt.Errorf("computed column %s: set either expr or template", spec.Name)
+		case spec.Expr != "":
+			column.expr, err = compileExpr(spec.Expr, extended.Headers)
+		case spec.Template != "":
+			column.tmpl, err = template.New(spec.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(spec.Template)
+			if err == nil {
+				err = checkTemplateFormats(column.tmpl.Tree.Root)
+			}
+		default:
+			return config, nil, fmt.Errorf("computed column %s: missing expr or template", spec.Name)
+		}
+		if err != nil {
+			return config, nil, fmt.Errorf("computed column %s: %v", spec.Name, err)
+		}
+		columns = append(columns, column)
+
+		extended.Headers = append(extended.Headers, spec.Name)
+		extended.Alignment = append(extended.Alignment, spec.Align)
+		extended.HeaderAlignment = append(extended.HeaderAlignment, "")
+		extended.Format = append(extended.Format, spec.Format)
+	}
+	extended.ComputedColumns = nil
+
+	return extended, columns, nil
+}
+
+// checkTemplateFormats parses the constant specifications passed to the
+// format function of a template, such as {{format "bytes" .Size}}
+func checkTemplateFormats(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateFormats(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkTemplateFormats(n.Pipe)
+	case *parse.IfNode:
+		return checkTemplateBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return checkTemplateBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkTemplateBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return checkTemplateFormats(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := checkTemplateFormats(cmd); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 1 {
+			fn, isIdent := n.Args[0].(*parse.IdentifierNode)
+			spec, isString := n.Args[1].(*parse.StringNode)
+			if isIdent && isString && fn.Ident == "format" {
+				if _, err := parseFormat(spec.Text); err != nil {
+					return err
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			if err := checkTemplateFormats(arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkTemplateBranch(n *parse.BranchNode) error {
+	for _, child := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if err := checkTemplateFormats(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value evaluates the column for a row holding the columns before it
+func (c computedColumn) value(row Row) (string, error) {
+	if c.expr != nil {
+		v, err := c.expr.eval(row)
+		if err != nil {
+			return "", err
+		}
+		return toText(v), nil
+	}
+
+	// Templates see each column by header name, with spaces also
+	// available as underscores: {{.Response_Time}} or {{index . "Response Time"}}
+	data := make(map[string]string, 2*len(c.headers))
+	for i, header := range c.headers {
+		name := strings.TrimSpace(cleanText(header))
+		data[name] = row.Text(i)
+		data[strings.ReplaceAll(name, " ", "_")] = row.Text(i)
+	}
+
+	var b strings.Builder
+	if err := c.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// computeRow appends the computed columns to a row with the given number
+// of source columns
+func computeRow(columns []computedColumn, row Row, width int) (Row, error) {
+	if len(columns) == 0 || row.Section != "" {
+		return row, nil
+	}
+
+	texts := make([]string, width, width+len(columns))
+	copy(texts, row.Texts())
+	row = row.withTexts(texts)
+
+	for _, column := range columns {
+		value, err := column.value(row)
+		if err != nil {
+			return row, fmt.Errorf("computed column %s: %v", column.name, err)
+		}
+		row.Cells = append(row.Cells, Cell{Text: value})
+	}
+	return row, nil
+}
@@ -0,0 +1,238 @@
+package tables
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// computeTexts adds the computed columns of config to its rows
+func computeTexts(config TableConfig) ([]string, [][]string, error) {
+	extended, columns, err := withComputedColumns(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows [][]string
+	for _, row := range config.Rows {
+		computed, err := computeRow(columns, row, len(config.Headers))
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, computed.Texts())
+	}
+	return extended.Headers, rows, nil
+}
+
+func TestComputedColumns(t *testing.T) {
+	headers := []string{"Disk", "Used", "Total", "Region"}
+	rows := [][]string{
+		{"sda", "25", "100", "eu west"},
+		{"sdb", "N/A", "200", "us"},
+		{"sdc", "1.5 GiB", "0", ""},
+	}
+
+	tests := []struct {
+		name    string
+		columns []ComputedColumn
+		want    [][]string // computed cells of each row
+	}{
+		{
+			name:    "arithmetic",
+			columns: []ComputedColumn{{Name: "Free", Expr: "Total - Used"}},
+			want:    [][]string{{"75"}, {""}, {""}},
+		},
+		{
+			name:    "division by zero",
+			columns: []ComputedColumn{{Name: "Usage", Expr: "Used / Total"}},
+			want:    [][]string{{"0.25"}, {""}, {""}},
+		},
+		{
+			name:    "text joins",
+			columns: []ComputedColumn{{Name: "Label", Expr: `Disk + "@" + Region`}},
+			want:    [][]string{{"sda@eu west"}, {"sdb@us"}, {"sdc@"}},
+		},
+		{
+			name: "functions of non-numeric input",
+			columns: []ComputedColumn{
+				{Name: "Abs", Expr: "abs(Used)"},
+				{Name: "Number", Expr: "coalesce(number(Used), 0)"},
+				{Name: "Max", Expr: "max(Used, Total)"},
+			},
+			want: [][]string{{"25", "25", "100"}, {"", "0", ""}, {"", "0", ""}},
+		},
+		{
+			name: "dependency order",
+			columns: []ComputedColumn{
+				{Name: "Free", Expr: "Total - Used"},
+				{Name: "Free Percent", Expr: "Free / Total * 100"},
+				{Name: "Summary", Template: "{{.Disk}}: {{.Free_Percent}}% free"},
+			},
+			want: [][]string{
+				{"75", "75", "sda: 75% free"},
+				{"", "", "sdb: % free"},
+				{"", "", "sdc: % free"},
+			},
+		},
+		{
+			name: "template functions",
+			columns: []ComputedColumn{
+				{Name: "Label", Template: `{{upper .Disk}} ({{index . "Region"}})`},
+				{Name: "Size", Template: `{{format "bytes" .Total}}`},
+			},
+			want: [][]string{{"SDA (eu west)", "100 B"}, {"SDB (us)", "200 B"}, {"SDC ()", "0 B"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := TableConfig{Headers: headers, Rows: TextRows(rows), ComputedColumns: tt.columns}
+			gotHeaders, gotRows, err := computeTexts(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantHeaders := append([]string(nil), headers...)
+			for _, column := range tt.columns {
+				wantHeaders = append(wantHeaders, column.Name)
+			}
+			if !reflect.DeepEqual(gotHeaders, wantHeaders) {
+				t.Errorf("headers = %q, want %q", gotHeaders, wantHeaders)
+			}
+			for i, row := range gotRows {
+				if !reflect.DeepEqual(row[len(headers):], tt.want[i]) {
+					t.Errorf("row %d computed = %q, want %q", i, row[len(headers):], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestComputedColumnErrors(t *testing.T) {
+	headers := []string{"Used", "Total"}
+
+	tests := []struct {
+		name    string
+		columns []ComputedColumn
+		want    string
+	}{
+		{
+			name:    "unknown column",
+			columns: []ComputedColumn{{Name: "Free", Expr: "Size - Used"}},
+			want:    `computed column Free: unknown column "Size" at position 1`,
+		},
+		{
+			name: "column computed later",
+			columns: []ComputedColumn{
+				{Name: "Percent", Expr: "Free / Total"},
+				{Name: "Free", Expr: "Total - Used"},
+			},
+			want: `computed column Percent: unknown column "Free"`,
+		},
+		{
+			name:    "self reference",
+			columns: []ComputedColumn{{Name: "Free", Expr: "Free + 1"}},
+			want:    `computed column Free: unknown column "Free"`,
+		},
+		{
+			name:    "unknown template column",
+			columns: []ComputedColumn{{Name: "Label", Template: "{{.Size}}"}},
+			want:    `computed column Label: template: Label:1:2: executing "Label" at <.Size>: map has no entry for key "Size"`,
+		},
+		{
+			name:    "template syntax",
+			columns: []ComputedColumn{{Name: "Label", Template: "{{.Used"}},
+			want:    "computed column Label: template: Label:1: unclosed action",
+		},
+		{
+			name:    "invalid expression",
+			columns: []ComputedColumn{{Name: "Free", Expr: "Total -"}},
+			want:    "computed column Free: unexpected end of expression",
+		},
+		{
+			name:    "missing name",
+			columns: []ComputedColumn{{Expr: "Total"}},
+			want:    "computed column: missing name",
+		},
+		{
+			name:    "expr and template",
+			columns: []ComputedColumn{{Name: "Free", Expr: "Total", Template: "{{.Total}}"}},
+			want:    "computed column Free: set either expr or template",
+		},
+		{
+			name:    "neither expr nor template",
+			columns: []ComputedColumn{{Name: "Free"}},
+			want:    "computed column Free: missing expr or template",
+		},
+		{
+			name:    "invalid format",
+			columns: []ComputedColumn{{Name: "Size", Template: `{{format "money" .Total}}`}},
+			want:    "unknown format: money",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := TableConfig{Headers: headers, Rows: TextRows([][]string{{"25", "100"}}), ComputedColumns: tt.columns}
+			_, _, err := computeTexts(config)
+			if err == nil {
+				t.Fatal("succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputedColumnsRender(t *testing.T) {
+	config := TableConfig{
+		Headers: []string{"Disk", "Used", "Total"},
+		Rows:    TextRows([][]string{{"sda", "25", "100"}, {"sdb", "90", "100"}}),
+		ComputedColumns: []ComputedColumn{
+			{Name: "Usage", Expr: "Used / Total", Format: "percent", Align: "right"},
+		},
+		Filter: "Usage > 0.5",
+	}
+
+	out := renderTable(t, config)
+	for _, want := range []string{"│ Disk │ Used │ Total │ Usage │", "│ sdb  │ 90   │ 100   │   90% │"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no line containing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "sda") {
+		t.Errorf("filtered row rendered:\n%s", out)
+	}
+}
+
+func TestComputedColumnFormatSpecs(t *testing.T) {
+	tests := []struct {
+		name   string
+		column ComputedColumn
+		want   string // error, empty when the column compiles
+	}{
+		{"expression", ComputedColumn{Name: "Size", Expr: `format(Total, "money")`}, "computed column Size: format at position 1: unknown format: money"},
+		{"expression printf", ComputedColumn{Name: "Size", Expr: `format(Total, "%s")`}, "unsupported verb %s"},
+		{"template", ComputedColumn{Name: "Size", Template: `{{format "money" .Total}}`}, "computed column Size: unknown format: money"},
+		{"template pipeline", ComputedColumn{Name: "Size", Template: `{{.Total | format "bytes:kb"}}`}, "invalid byte units"},
+		{"template branch", ComputedColumn{Name: "Size", Template: `{{if .Total}}{{else}}{{format "fixed:x" .Used}}{{end}}`}, "invalid decimals"},
+		{"valid", ComputedColumn{Name: "Size", Template: `{{format "bytes" .Total}} {{.Used | format "percent:1"}}`}, ""},
+		{"spec from a column", ComputedColumn{Name: "Size", Expr: `format(Total, Used)`}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No rows: invalid specifications fail when the column is compiled
+			config := TableConfig{Headers: []string{"Used", "Total"}, ComputedColumns: []ComputedColumn{tt.column}}
+			_, _, err := withComputedColumns(config)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("error = %v, want none", err)
+			case tt.want != "" && err == nil:
+				t.Fatal("compiled, want error")
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
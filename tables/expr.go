@@ -0,0 +1,630 @@
+package tables
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the lexical class of an expression token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+// token is a lexical unit of an expression
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// exprOperators lists the operators, longest first so that "<=" wins over "<"
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "=", "+", "-", "*", "/", ","}
+
+// lexExpr splits an expression into tokens
+func lexExpr(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')':
+			kind := tokLParen
+			if r == ')' {
+				kind = tokRParen
+			}
+			tokens = append(tokens, token{kind: kind, text: string(r), pos: i})
+			i++
+
+		case r == '"' || r == '\'':
+			start := i
+			var text strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokString, text: text.String(), pos: start})
+
+		case r == '`':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '`' {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated column name at position %d", start+1)
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start+1 : i]), pos: start})
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			// Numbers may carry a unit or percent sign, e.g. 100ms or 25%
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || unicode.IsLetter(runes[i]) ||
+				runes[i] == '.' || runes[i] == '%' || runes[i] == '_') {
+				i++
+			}
+			text := string(runes[start:i])
+			num, ok := parseNumber(text)
+			if !ok {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, start+1)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: num, pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: start})
+
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i+1)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+// exprNode is a compiled expression evaluated against a row
+type exprNode interface {
+	eval(row Row) (any, error)
+}
+
+// literalNode is a constant string, number or boolean
+type literalNode struct {
+	value any
+}
+
+func (n literalNode) eval(Row) (any, error) {
+	return n.value, nil
+}
+
+// columnNode reads the raw text of a cell
+type columnNode struct {
+	column int
+}
+
+func (n columnNode) eval(row Row) (any, error) {
+	return row.Text(n.column), nil
+}
+
+// notNode negates the truth value of its operand
+type notNode struct {
+	operand exprNode
+}
+
+func (n notNode) eval(row Row) (any, error) {
+	v, err := n.operand.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+// logicalNode implements short-circuit && and ||
+type logicalNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n logicalNode) eval(row Row) (any, error) {
+	l, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" && !truthy(l) {
+		return false, nil
+	}
+	if n.op == "||" && truthy(l) {
+		return true, nil
+	}
+	r, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+// compareNode implements comparison and regular expression operators
+type compareNode struct {
+	op          string
+	left, right exprNode
+	pattern     *regexp.Regexp
+}
+
+func (n compareNode) eval(row Row) (any, error) {
+	l, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.pattern != nil {
+		matched := n.pattern.MatchString(cleanText(toText(l)))
+		return matched == (n.op == "=~"), nil
+	}
+
+	r, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := compareExprValues(l, r)
+	if !ok {
+		// A number and text without a number only differ
+		return n.op == "!=", nil
+	}
+
+	switch n.op {
+	case "==", "=":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default: // ">="
+		return c >= 0, nil
+	}
+}
+
+// negNode negates a number
+type negNode struct {
+	operand exprNode
+}
+
+func (n negNode) eval(row Row) (any, error) {
+	v, err := n.operand.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	if x, ok := toNumber(v); ok {
+		return -x, nil
+	}
+	return nil, nil
+}
+
+// arithNode implements + - * /. Operands that are not numbers produce an
+// empty value, except for + which then joins text operands. Division by
+// zero is empty as well.
+type arithNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n arithNode) eval(row Row) (any, error) {
+	l, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	x, okL := toNumber(l)
+	y, okR := toNumber(r)
+	if !okL || !okR {
+		if n.op == "+" && l != nil && r != nil {
+			return toText(l) + toText(r), nil
+		}
+		return nil, nil
+	}
+
+	switch n.op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	default: // "/"
+		if y == 0 {
+			return nil, nil
+		}
+		return x / y, nil
+	}
+}
+
+// callNode calls a built-in function
+type callNode struct {
+	fn   exprFunc
+	args []exprNode
+}
+
+func (n callNode) eval(row Row) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return n.fn.call(args)
+}
+
+// compareExprValues compares two values numerically when both coerce to
+// numbers (using the same rules as column formats), otherwise as text. Text
+// compared with a number uses the number it starts with, so "45/100" > 10;
+// text without one is not comparable.
+func compareExprValues(a, b any) (int, bool) {
+	x, okA := toNumber(a)
+	y, okB := toNumber(b)
+	if isNumber(a) != isNumber(b) {
+		if !okA {
+			x, okA = leadingNumber(toText(a))
+		}
+		if !okB {
+			y, okB = leadingNumber(toText(b))
+		}
+		if !okA || !okB {
+			return 0, false
+		}
+	}
+	if okA && okB {
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	return strings.Compare(cleanText(toText(a)), cleanText(toText(b))), true
+}
+
+func isNumber(v any) bool {
+	_, ok := v.(float64)
+	return ok
+}
+
+func toNumber(v any) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case string:
+		return parseNumber(value)
+	}
+	return 0, false
+}
+
+func toText(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
+
+func truthy(v any) bool {
+	switch value := v.(type) {
+	case bool:
+		return value
+	case float64:
+		return value != 0
+	case string:
+		return strings.TrimSpace(cleanText(value)) != ""
+	}
+	return false
+}
+
+// exprParser builds an expression tree from tokens
+type exprParser struct {
+	tokens  []token
+	pos     int
+	headers []string
+}
+
+// compileExpr parses an expression, resolving column names against headers.
+// Columns are referenced by name, with underscores standing for spaces, or
+// quoted in backticks: `Response Time` > 100ms.
+func compileExpr(src string, headers []string) (exprNode, error) {
+	tokens, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens, headers: headers}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+	return node, nil
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) isOp(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.isOp("!") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("==", "=", "!=", "<", "<=", ">", ">=", "=~", "!~") {
+		return left, nil
+	}
+
+	op := p.next()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	node := compareNode{op: op.text, left: left, right: right}
+	if op.text == "=~" || op.text == "!~" {
+		lit, ok := right.(literalNode)
+		pattern, isString := lit.value.(string)
+		if !ok || !isString {
+			return nil, fmt.Errorf("operator %s at position %d needs a string pattern", op.text, op.pos+1)
+		}
+		node.pattern, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return node, nil
+}
+
+// parseOperand parses the operand of a comparison: an arithmetic
+// expression of + and - over * and /
+func (p *exprParser) parseOperand() (exprNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = arithNode{op: op.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseTerm() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/") {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithNode{op: op.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.isOp("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return literalNode{value: tok.num}, nil
+	case tokString:
+		return literalNode{value: tok.text}, nil
+	case tokIdent:
+		return p.parseIdent(tok)
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos+1)
+		}
+		return node, nil
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+}
+
+// parseIdent resolves a keyword, function call or column reference
+func (p *exprParser) parseIdent(tok token) (exprNode, error) {
+	switch strings.ToLower(tok.text) {
+	case "true":
+		return literalNode{value: true}, nil
+	case "false":
+		return literalNode{value: false}, nil
+	}
+
+	if p.peek().kind == tokLParen {
+		return p.parseCall(tok)
+	}
+
+	column, err := columnIndex(p.headers, tok.text)
+	if err != nil {
+		column, err = columnIndex(p.headers, strings.ReplaceAll(tok.text, "_", " "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unknown column %q at position %d", tok.text, tok.pos+1)
+	}
+	return columnNode{column: column}, nil
+}
+
+// parseCall parses the arguments of a function call
+func (p *exprParser) parseCall(name token) (exprNode, error) {
+	fn, ok := exprFuncs[strings.ToLower(name.text)]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos+1)
+	}
+	p.next() // (
+
+	var args []exprNode
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if closing := p.next(); closing.kind != tokRParen {
+		return nil, fmt.Errorf("expected ) at position %d", closing.pos+1)
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments for %s at position %d", name.text, name.pos+1)
+	}
+
+	// Check constant format specifications once instead of on every row
+	if spec, ok := args[len(args)-1].(literalNode); ok && strings.EqualFold(name.text, "format") {
+		if _, err := parseFormat(toText(spec.value)); err != nil {
+			return nil, fmt.Errorf("format at position %d: %v", name.pos+1, err)
+		}
+	}
+	return callNode{fn: fn, args: args}, nil
+}
@@ -0,0 +1,213 @@
+package tables
+
+import (
+	"strings"
+	"testing"
+)
+
+var exprHeaders = []string{"Database", "Status", "Connections", "Response Time", "Load"}
+
+// exprRow is a row of example2.json with a numeric Load column
+var exprRow = TextRow("**Primary DB**", "Online", "45/100", "12ms", "0.75")
+
+// evalExpr compiles and evaluates an expression against exprRow
+func evalExpr(t *testing.T, src string) any {
+	t.Helper()
+	node, err := compileExpr(src, exprHeaders)
+	if err != nil {
+		t.Fatalf("compileExpr(%q): %v", src, err)
+	}
+	v, err := node.eval(exprRow)
+	if err != nil {
+		t.Fatalf("eval(%q): %v", src, err)
+	}
+	return v
+}
+
+func TestExprFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		// Comparisons and coercion
+		{`Status == "Online"`, true},
+		{`Status = "Online"`, true},
+		{`Status != "Offline"`, true},
+		{`Database == "Primary DB"`, true},
+		{`Load > 0.5`, true},
+		{`Load <= 75%`, true},
+		{`Load < .7`, false},
+		{`Load >= 1`, false},
+		{`Status > "Offline"`, true},
+		{`Connections > 10`, true},
+		{`Connections < 45`, false},
+		{`Connections == 45`, true},
+		{`Connections == "45/100"`, true},
+		{`Status > 10`, false},
+		{`Status != 10`, true},
+
+		// Durations compare in seconds
+		{"`Response Time` < 100ms", true},
+		{"`Response Time` > 0.01", true},
+		{"`Response Time` == 12ms", true},
+		{"Response_Time < 1s", true},
+
+		// Precedence: ! over comparison over && over ||
+		{`Status == "Offline" && Load > 0 || Load > 0.5`, true},
+		{`Status == "Offline" && (Load > 0 || Load > 0.5)`, false},
+		{`Load > 0.5 || Status == "Offline" && Load > 1`, true},
+		{`(Load > 0.5 || Status == "Offline") && Load > 1`, false},
+		{`!Status == "Online"`, false},
+		{`!(Status == "Offline")`, true},
+		{`!!true`, true},
+		{`Load * 100 > 70`, true},
+		{`1 + 2 * 3 == 7`, true},
+		{`(1 + 2) * 3 == 9`, true},
+		{`10 - 2 - 3 == 5`, true},
+		{`-Load < 0`, true},
+
+		// Quoting
+		{`Status == 'Online'`, true},
+		{`"it's" == 'it\'s'`, true},
+		{`"say \"hi\"" =~ "\"hi\""`, true},
+
+		// Regular expressions
+		{`Database =~ "^Primary"`, true},
+		{`Database !~ "(?i)replica"`, true},
+		{`Status =~ "^off"`, false},
+
+		// Truthiness and functions
+		{`Status`, true},
+		{`false || Load`, true},
+		{`len(Status) == 6`, true},
+		{`upper(Status) == "ONLINE"`, true},
+		{`number(Connections) > 0`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := truthy(evalExpr(t, tt.expr)); got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprValues(t *testing.T) {
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`Load * 2`, 1.5},
+		{`Status + "!"`, "Online!"},
+		{`Load / 0`, nil},
+		{`Status * 2`, nil},
+		{`1.5s + 500ms`, 2.0},
+		{`25% * 4`, 1.0},
+		{`1_000 + 1`, 1001.0},
+		{"`Response Time` * 1000", 12.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := evalExpr(t, tt.expr); got != tt.want {
+				t.Errorf("%s = %#v, want %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprIdentifiers(t *testing.T) {
+	headers := []string{"Response Time", "Response_Time", "v1.2", "Total Cost"}
+	row := TextRow("a", "b", "c", "d")
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"`Response Time`", "a"},
+		{"Response_Time", "b"},
+		{"v1.2", "c"},
+		{"Total_Cost", "d"},
+		{"`total cost`", "d"},
+		{"TOTAL_COST", "d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			node, err := compileExpr(tt.expr, headers)
+			if err != nil {
+				t.Fatalf("compileExpr(%q): %v", tt.expr, err)
+			}
+			v, err := node.eval(row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tt.want {
+				t.Errorf("%s = %#v, want %q", tt.expr, v, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`Status == "Online`, "unterminated string at position 11"},
+		{"`Status == 1", "unterminated column name at position 1"},
+		{`Load > 12xyz`, `invalid number "12xyz" at position 8`},
+		{`Load > 1 # 2`, `unexpected character '#' at position 10`},
+		{`Uptime > 1`, `unknown column "Uptime" at position 1`},
+		{`Load >`, "unexpected end of expression"},
+		{``, "unexpected end of expression"},
+		{`(Load > 1`, "expected ) at position 10"},
+		{`Load > 1)`, `unexpected ")" at position 9`},
+		{`Load 1`, `unexpected "1" at position 6`},
+		{`Status =~ Load`, "operator =~ at position 8 needs a string pattern"},
+		{`Status =~ "("`, `invalid pattern "("`},
+		{`nosuch(Load)`, `unknown function "nosuch" at position 1`},
+		{`len(Status, Load)`, "wrong number of arguments for len at position 1"},
+		{`len(Status`, "expected ) at position 11"},
+		{`&& Load`, `unexpected "&&" at position 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := compileExpr(tt.expr, exprHeaders)
+			if err == nil {
+				t.Fatalf("compileExpr(%q) succeeded, want error", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("compileExpr(%q) error = %q, want it to contain %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeadingNumber(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  float64
+		valid bool
+	}{
+		{"45/100", 45, true},
+		{"12ms avg", 0.012, true},
+		{"**78**/80", 78, true},
+		{"$1,200 (est.)", 1200, true},
+		{"2024-01-02", 2024, true},
+		{"45", 45, true},
+		{"45min", 0, false},
+		{"N/A", 0, false},
+		{"Online", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := leadingNumber(tt.text)
+		if ok != tt.valid || got != tt.want {
+			t.Errorf("leadingNumber(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package tables
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Number format kinds accepted in TableConfig.Format
+const (
+	FormatNumber     = "number"
+	FormatFixed      = "fixed"
+	FormatPercent    = "percent"
+	FormatCurrency   = "currency"
+	FormatScientific = "scientific"
+	FormatBytes      = "bytes"
+	FormatDuration   = "duration"
+)
+
+// numberFormat is a parsed column format specification
+type numberFormat struct {
+	kind     string
+	printf   string
+	verb     byte // verb of the printf format
+	decimals int  // -1 keeps the shortest representation
+	symbol   string
+	si       bool
+}
+
+// parseFormat parses a format specification such as "number:2",
+// "currency:€", "percent:1", "bytes:si" or a printf verb like "%.3f"
+func parseFormat(spec string) (numberFormat, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "none" {
+		return numberFormat{}, nil
+	}
+	if strings.HasPrefix(spec, "%") {
+		verb, err := printfVerb(spec)
+		if err != nil {
+			return numberFormat{}, err
+		}
+		return numberFormat{kind: "printf", printf: spec, verb: verb}, nil
+	}
+
+	parts := strings.Split(spec, ":")
+	f := numberFormat{kind: strings.ToLower(parts[0]), decimals: -1}
+	args := parts[1:]
+
+	decimals := func(arg string, fallback int) (int, error) {
+		if arg == "" {
+			return fallback, nil
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid decimals %q in format %q", arg, spec)
+		}
+		return n, nil
+	}
+
+	var err error
+	switch f.kind {
+	case FormatNumber, "thousands":
+		f.kind = FormatNumber
+		if len(args) > 0 {
+			f.decimals, err = decimals(args[0], -1)
+		}
+	case FormatFixed:
+		f.decimals = 2
+		if len(args) > 0 {
+			f.decimals, err = decimals(args[0], 2)
+		}
+	case FormatPercent:
+		f.decimals = 0
+		if len(args) > 0 {
+			f.decimals, err = decimals(args[0], 0)
+		}
+	case FormatCurrency:
+		f.symbol = "$"
+		f.decimals = 2
+		if len(args) > 0 && args[0] != "" {
+			f.symbol = args[0]
+		}
+		if len(args) > 1 {
+			f.decimals, err = decimals(args[1], 2)
+		}
+	case FormatScientific:
+		f.decimals = 2
+		if len(args) > 0 {
+			f.decimals, err = decimals(args[0], 2)
+		}
+	case FormatBytes:
+		if len(args) > 0 {
+			switch strings.ToLower(args[0]) {
+			case "si":
+				f.si = true
+			case "iec", "":
+			default:
+				return f, fmt.Errorf("invalid byte units %q in format %q", args[0], spec)
+			}
+		}
+	case FormatDuration:
+	default:
+		return f, fmt.Errorf("unknown format: %s", spec)
+	}
+	return f, err
+}
+
+// apply formats cell text when it holds a number, leaving other text unchanged
+func (f numberFormat) apply(text string) string {
+	if f.kind == "" {
+		return text
+	}
+
+	bold := strings.HasPrefix(text, "**") && strings.HasSuffix(text, "**") && len(text) > 4
+	value, ok := parseNumber(text)
+	if !ok {
+		return text
+	}
+
+	var result string
+	switch f.kind {
+	case "printf":
+		if result, ok = formatPrintf(f.printf, f.verb, value); !ok {
+			return text
+		}
+	case FormatNumber:
+		result = groupThousands(strconv.FormatFloat(value, 'f', f.decimals, 64))
+	case FormatFixed:
+		result = strconv.FormatFloat(value, 'f', f.decimals, 64)
+	case FormatPercent:
+		result = strconv.FormatFloat(value*100, 'f', f.decimals, 64) + "%"
+	case FormatCurrency:
+		result = groupThousands(strconv.FormatFloat(math.Abs(value), 'f', f.decimals, 64))
+		result = f.symbol + result
+		if value < 0 {
+			result = "-" + result
+		}
+	case FormatScientific:
+		result = strconv.FormatFloat(value, 'e', f.decimals, 64)
+	case FormatBytes:
+		result = formatBytes(value, f.si)
+	case FormatDuration:
+		result = formatDuration(value)
+	}
+
+	if bold {
+		return "**" + result + "**"
+	}
+	return result
+}
+
+// Verbs accepted in printf formats
+const (
+	printfIntVerbs   = "dxXob"
+	printfFloatVerbs = "fFeEgGv"
+)
+
+// printfVerb returns the verb of a printf format, which must have exactly
+// one number verb with optional flags, width and precision. Literal text and
+// "%%" may follow it.
+func printfVerb(format string) (byte, error) {
+	var verb byte
+	verbs := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		for i < len(format) && (isDigit(format[i]) || format[i] == '.') {
+			i++
+		}
+		if i == len(format) {
+			return 0, fmt.Errorf("printf format %q has no verb", format)
+		}
+		if strings.IndexByte(printfIntVerbs+printfFloatVerbs, format[i]) < 0 {
+			r, _ := utf8.DecodeRuneInString(format[i:])
+			return 0, fmt.Errorf("unsupported verb %%%c in printf format %q", r, format)
+		}
+		verb = format[i]
+		verbs++
+	}
+	if verbs != 1 {
+		return 0, fmt.Errorf("printf format %q must have exactly one verb", format)
+	}
+	return verb, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// formatPrintf applies a checked printf format, passing integers to integer
+// verbs. Values outside the int64 range cannot take an integer verb and
+// report false.
+func formatPrintf(format string, verb byte, value float64) (string, bool) {
+	if strings.IndexByte(printfIntVerbs, verb) < 0 {
+		return fmt.Sprintf(format, value), true
+	}
+	if value < math.MinInt64 || value >= math.MaxInt64 {
+		return "", false
+	}
+	return fmt.Sprintf(format, int64(value)), true
+}
+
+// parseNumber extracts a number from cell text. Bold markers, surrounding
+// spaces, a leading currency symbol and thousands separators are ignored; a
+// trailing "%" divides by 100 and duration strings such as "12ms" yield
+// seconds. It is the single coercion rule shared by all numeric features.
+func parseNumber(text string) (float64, bool) {
+	s := strings.TrimSpace(cleanText(text))
+	if s == "" {
+		return 0, false
+	}
+
+	if d, err := time.ParseDuration(s); err == nil && strings.IndexFunc(s, isLetter) >= 0 {
+		return d.Seconds(), true
+	}
+
+	negative := false
+	if s[0] == '-' || s[0] == '+' {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	for _, symbol := range []string{"$", "€", "£", "¥"} {
+		if strings.HasPrefix(s, symbol) {
+			s = s[len(symbol):]
+			break
+		}
+	}
+
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = strings.TrimSuffix(s, "%")
+		scale = 0.01
+	}
+
+	s = strings.NewReplacer(",", "", "_", "").Replace(s)
+	if s == "" || s[0] == '-' || s[0] == '+' {
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	if negative {
+		value = -value
+	}
+	return value * scale, true
+}
+
+// leadingNumber extracts the number at the start of cell text, such as 45
+// from "45/100" or 12ms from "12ms avg". The number must end at a character
+// that cannot continue it, so "45min" has none.
+func leadingNumber(text string) (float64, bool) {
+	if value, ok := parseNumber(text); ok {
+		return value, true
+	}
+	s := strings.TrimSpace(cleanText(text))
+	for end := len(s) - 1; end > 0; end-- {
+		r, _ := utf8.DecodeRuneInString(s[end:])
+		if r == utf8.RuneError || isLetter(r) || isDigit(s[end]) || strings.ContainsRune(".,_%", r) {
+			continue
+		}
+		if value, ok := parseNumber(s[:end]); ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == 'µ'
+}
+
+// groupThousands inserts commas into the integer part of a formatted number
+func groupThousands(number string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(number, ".")
+	var grouped strings.Builder
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if hasFrac {
+		return sign + grouped.String() + "." + fracPart
+	}
+	return sign + grouped.String()
+}
+
+// formatBytes renders a byte count with binary (KiB) or decimal (kB) units
+func formatBytes(value float64, si bool) string {
+	base, units := 1024.0, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	if si {
+		base, units = 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+	}
+
+	size, unit := math.Abs(value), 0
+	for size >= base && unit < len(units)-1 {
+		size /= base
+		unit++
+	}
+	if value < 0 {
+		size = -size
+	}
+
+	if unit == 0 {
+		return strconv.FormatFloat(size, 'f', -1, 64) + " " + units[0]
+	}
+	return strconv.FormatFloat(size, 'f', 1, 64) + " " + units[unit]
+}
+
+// formatDuration renders a number of seconds using its two largest units
+func formatDuration(seconds float64) string {
+	sign := ""
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+
+	switch {
+	case seconds == 0:
+		return "0s"
+	case seconds < 1e-3:
+		return sign + strconv.FormatFloat(seconds*1e6, 'f', -1, 64) + "µs"
+	case seconds < 1:
+		return sign + strconv.FormatFloat(math.Round(seconds*1e4)/10, 'f', -1, 64) + "ms"
+	case seconds < 60:
+		return sign + strconv.FormatFloat(math.Round(seconds*100)/100, 'f', -1, 64) + "s"
+	}
+
+	units := []struct {
+		name string
+		size int64
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}}
+
+	total := int64(math.Round(seconds))
+	for i, u := range units {
+		if total < u.size {
+			continue
+		}
+		result := strconv.FormatInt(total/u.size, 10) + u.name
+		if i+1 < len(units) {
+			next := units[i+1]
+			if rest := total % u.size / next.size; rest > 0 {
+				result += " " + strconv.FormatInt(rest, 10) + next.name
+			}
+		}
+		return sign + result
+	}
+	return sign + "0s"
+}
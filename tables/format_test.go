@@ -0,0 +1,121 @@
+package tables
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatApply(t *testing.T) {
+	tests := []struct {
+		spec, text, want string
+	}{
+		{"number", "1234567.891", "1,234,567.891"},
+		{"number:2", "1234567.891", "1,234,567.89"},
+		{"thousands", "-1234", "-1,234"},
+		{"fixed:1", "3.14159", "3.1"},
+		{"fixed", "3", "3.00"},
+		{"percent", "0.256", "26%"},
+		{"percent:1", "0.256", "25.6%"},
+		{"currency", "-1234.56", "-$1,234.56"},
+		{"currency:€:0", "-1234.56", "-€1,235"},
+		{"scientific:3", "123456", "1.235e+05"},
+		{"bytes", "1536", "1.5 KiB"},
+		{"bytes:si", "1536", "1.5 kB"},
+		{"bytes", "512", "512 B"},
+		{"duration", "3725", "1h 2m"},
+		{"duration", "12ms", "12ms"},
+		{"none", "1234", "1234"},
+		{"", "1234", "1234"},
+
+		// Input coercion
+		{"number", "$1,234", "1,234"},
+		{"fixed:2", "50%", "0.50"},
+		{"number", "**1234**", "**1,234**"},
+		{"number", "N/A", "N/A"},
+		{"number", "45/100", "45/100"},
+
+		// printf verbs
+		{"%05.1f", "3.14159", "003.1"},
+		{"%.2e", "123456", "1.23e+05"},
+		{"%g", "0.5", "0.5"},
+		{"%d", "42.9", "42"},
+		{"%x", "255", "ff"},
+		{"%08b", "5", "00000101"},
+		{"%d items", "3", "3 items"},
+		{"%.1f%%", "12.34", "12.3%"},
+		{"%d", "1e300", "1e300"},
+		{"%x", "-1e19", "-1e19"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.text, func(t *testing.T) {
+			got, err := formatText(tt.text, tt.spec)
+			if err != nil {
+				t.Fatalf("formatText(%q, %q): %v", tt.text, tt.spec, err)
+			}
+			if got != tt.want {
+				t.Errorf("formatText(%q, %q) = %q, want %q", tt.text, tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormatErrors(t *testing.T) {
+	tests := []struct {
+		spec, want string
+	}{
+		{"%", "has no verb"},
+		{"%.2", "has no verb"},
+		{"%s", "unsupported verb %s"},
+		{"%q", "unsupported verb %q"},
+		{"%*d", "unsupported verb %*"},
+		{"%%", "exactly one verb"},
+		{"%d/%d", "exactly one verb"},
+		{"number:x", "invalid decimals"},
+		{"fixed:-1", "invalid decimals"},
+		{"bytes:kb", "invalid byte units"},
+		{"money", "unknown format"},
+		{"decimal:2", "unknown format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := parseFormat(tt.spec)
+			if err == nil {
+				t.Fatalf("parseFormat(%q) succeeded, want error", tt.spec)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseFormat(%q) error = %q, want it to contain %q", tt.spec, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  float64
+		valid bool
+	}{
+		{"42", 42, true},
+		{" -1,234.5 ", -1234.5, true},
+		{"+€3", 3, true},
+		{"25%", 0.25, true},
+		{"1_000", 1000, true},
+		{"1.5s", 1.5, true},
+		{"250ms", 0.25, true},
+		{"**7**", 7, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"--1", 0, false},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.text)
+		if ok != tt.valid || got != tt.want {
+			t.Errorf("parseNumber(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package tables
+
+import (
+	"math"
+	"strings"
+)
+
+// exprFunc is a built-in function callable from expressions. A negative
+// maxArgs accepts any number of arguments.
+type exprFunc struct {
+	minArgs int
+	maxArgs int
+	call    func(args []any) (any, error)
+}
+
+// numeric wraps a function of numbers; it returns an empty value when an
+// argument is not a number
+func numeric(minArgs, maxArgs int, fn func(x []float64) float64) exprFunc {
+	return exprFunc{minArgs: minArgs, maxArgs: maxArgs, call: func(args []any) (any, error) {
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			v, ok := toNumber(arg)
+			if !ok {
+				return nil, nil
+			}
+			values[i] = v
+		}
+		return fn(values), nil
+	}}
+}
+
+// textual wraps a function of a single text argument
+func textual(fn func(s string) any) exprFunc {
+	return exprFunc{minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+		return fn(toText(args[0])), nil
+	}}
+}
+
+// exprFuncs lists the functions available in expressions
+var exprFuncs = map[string]exprFunc{
+	"abs":   numeric(1, 1, func(x []float64) float64 { return math.Abs(x[0]) }),
+	"floor": numeric(1, 1, func(x []float64) float64 { return math.Floor(x[0]) }),
+	"ceil":  numeric(1, 1, func(x []float64) float64 { return math.Ceil(x[0]) }),
+	"round": numeric(1, 2, func(x []float64) float64 {
+		if len(x) == 1 {
+			return math.Round(x[0])
+		}
+		scale := math.Pow(10, math.Round(x[1]))
+		return math.Round(x[0]*scale) / scale
+	}),
+	"min": numeric(1, -1, func(x []float64) float64 {
+		result := x[0]
+		for _, v := range x[1:] {
+			result = min(result, v)
+		}
+		return result
+	}),
+	"max": numeric(1, -1, func(x []float64) float64 {
+		result := x[0]
+		for _, v := range x[1:] {
+			result = max(result, v)
+		}
+		return result
+	}),
+	"number": {minArgs: 1, maxArgs: 1, call: func(args []any) (any, error) {
+		if v, ok := toNumber(args[0]); ok {
+			return v, nil
+		}
+		return nil, nil
+	}},
+
+	"upper": textual(func(s string) any { return strings.ToUpper(s) }),
+	"lower": textual(func(s string) any { return strings.ToLower(s) }),
+	"trim":  textual(func(s string) any { return strings.TrimSpace(s) }),
+	"len":   textual(func(s string) any { return float64(getDisplayLength(s)) }),
+	"concat": {minArgs: 1, maxArgs: -1, call: func(args []any) (any, error) {
+		var b strings.Builder
+		for _, arg := range args {
+			b.WriteString(toText(arg))
+		}
+		return b.String(), nil
+	}},
+	"replace": {minArgs: 3, maxArgs: 3, call: func(args []any) (any, error) {
+		return strings.ReplaceAll(toText(args[0]), toText(args[1]), toText(args[2])), nil
+	}},
+
+	// if(condition, then[, else])
+	"if": {minArgs: 2, maxArgs: 3, call: func(args []any) (any, error) {
+		if truthy(args[0]) {
+			return args[1], nil
+		}
+		if len(args) == 3 {
+			return args[2], nil
+		}
+		return nil, nil
+	}},
+	// coalesce returns its first non-empty argument
+	"coalesce": {minArgs: 1, maxArgs: -1, call: func(args []any) (any, error) {
+		for _, arg := range args {
+			if truthy(arg) || isNumber(arg) {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	}},
+	// format applies a column format such as "bytes" or "percent:1"
+	"format": {minArgs: 2, maxArgs: 2, call: func(args []any) (any, error) {
+		return formatText(toText(args[0]), toText(args[1]))
+	}},
+}
+
+// formatText applies a format specification to a single value
+func formatText(text, spec string) (string, error) {
+	f, err := parseFormat(spec)
+	if err != nil {
+		return "", err
+	}
+	return f.apply(text), nil
+}
@@ -0,0 +1,113 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subtotal is a parsed entry of TableConfig.Subtotals
+type subtotal struct {
+	column    int
+	aggregate string
+}
+
+// grouping holds the compiled group_by and subtotals options
+type grouping struct {
+	column    int
+	header    string
+	subtotals []subtotal
+	label     int // column holding the subtotal label, -1 for none
+}
+
+// newGrouping resolves the grouping options against the source headers. The
+// subtotal label goes in the first displayed column that is not subtotalled.
+func newGrouping(config TableConfig, displayed []int) (*grouping, error) {
+	column, err := columnIndex(config.Headers, config.GroupBy)
+	if err != nil {
+		return nil, fmt.Errorf("group_by: %v", err)
+	}
+
+	g := &grouping{column: column, header: cleanText(config.Headers[column]), label: -1}
+	for _, spec := range config.Subtotals {
+		name, kind, _ := strings.Cut(spec, ":")
+		col, err := columnIndex(config.Headers, name)
+		if err != nil {
+			return nil, fmt.Errorf("subtotals: %v", err)
+		}
+		agg, err := parseAggregate(kind)
+		if err != nil {
+			return nil, fmt.Errorf("subtotals: %v", err)
+		}
+		g.subtotals = append(g.subtotals, subtotal{column: col, aggregate: agg})
+	}
+
+	for _, source := range displayed {
+		if !g.isSubtotal(source) {
+			g.label = source
+			break
+		}
+	}
+
+	return g, nil
+}
+
+func (g *grouping) isSubtotal(column int) bool {
+	for _, s := range g.subtotals {
+		if s.column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// apply clusters rows by the group column, keeping groups in order of first
+// appearance, and inserts a section row before each group followed by an
+// optional subtotal row
+func (g *grouping) apply(rows []Row, width int) []Row {
+	var order []string
+	groups := map[string][]Row{}
+	for _, row := range rows {
+		key := strings.TrimSpace(cleanText(row.Text(g.column)))
+		if _, exists := groups[key]; !exists {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+
+	result := make([]Row, 0, len(rows)+2*len(order))
+	for _, key := range order {
+		title := key
+		if title == "" {
+			title = "(none)"
+		}
+		result = append(result, Row{Section: g.header + ": " + title})
+		result = append(result, groups[key]...)
+
+		if len(g.subtotals) > 0 {
+			result = append(result, g.subtotalRow(groups[key], width))
+		}
+	}
+	return result
+}
+
+// subtotalRow aggregates the subtotal columns of a group into a bold row
+func (g *grouping) subtotalRow(rows []Row, width int) Row {
+	row := Row{Cells: make([]Cell, width)}
+	bold := func(col int, text string) {
+		row.Cells[col] = Cell{Text: text, Style: &CellStyle{Bold: true}}
+	}
+	if g.label >= 0 && g.label < width {
+		bold(g.label, "Subtotal")
+	}
+
+	for _, s := range g.subtotals {
+		values := make([]string, len(rows))
+		for i, row := range rows {
+			values[i] = row.Text(s.column)
+		}
+		if value := aggregate(s.aggregate, values); value != "" && s.column < width {
+			bold(s.column, value)
+		}
+	}
+	return row
+}
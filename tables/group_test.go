@@ -0,0 +1,106 @@
+package tables
+
+import (
+	"strings"
+	"testing"
+)
+
+// groupConfig is the grouping example of the readme
+var groupConfig = TableConfig{
+	Headers:   []string{"Database", "Status", "Connections"},
+	Rows:      TextRows([][]string{{"users-db", "Online", "142"}, {"orders-db", "Offline", "0"}, {"cache", "Online", "87"}}),
+	Alignment: []string{"left", "left", "right"},
+	Columns:   []ColumnSpec{{Name: "Database"}, {Name: "Connections"}},
+	GroupBy:   "Status",
+	Subtotals: []string{"Connections"},
+}
+
+func TestGroupRender(t *testing.T) {
+	out := renderTable(t, groupConfig)
+	want := []string{
+		"│ Status: Online          │",
+		"│ users-db  │         142 │",
+		"│ cache     │          87 │",
+		"│ Subtotal  │         229 │",
+		"│ Status: Offline         │",
+		"│ orders-db │           0 │",
+		"│ Subtotal  │           0 │",
+	}
+	last := -1
+	for _, line := range want {
+		i := strings.Index(out[last+1:], line)
+		if i < 0 {
+			t.Fatalf("output has no line containing %q after the previous one:\n%s", line, out)
+		}
+		last += 1 + i
+	}
+}
+
+func TestSubtotalRow(t *testing.T) {
+	tests := []struct {
+		name      string
+		subtotals []string
+		format    []string
+		want      string
+	}{
+		{"sum", []string{"Connections"}, nil, "│ Subtotal  │         229 │"},
+		{"average", []string{"Connections:avg"}, nil, "│ Subtotal  │       114.5 │"},
+		{"count", []string{"Connections:count"}, nil, "│ Subtotal  │           2 │"},
+		{"formatted", []string{"Connections"}, []string{"", "", "fixed:1"}, "│ Subtotal  │       229.0 │"},
+		{"label column subtotalled", []string{"Connections", "Database:count"}, nil, "│ 2         │         229 │"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := groupConfig
+			config.Subtotals = tt.subtotals
+			config.Format = tt.format
+			if out := renderTable(t, config); !strings.Contains(out, tt.want) {
+				t.Errorf("output has no line containing %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestSubtotalBold(t *testing.T) {
+	renderer := &ASCIITableRenderer{Style: tableStyles["single-line-full"], ANSI: true}
+	out, err := RenderString(renderer, groupConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "│\x1b[1m Subtotal  \x1b[0m│\x1b[1m         229 \x1b[0m│"
+	if !strings.Contains(out, want) {
+		t.Errorf("output has no line containing %q:\n%q", want, out)
+	}
+	if strings.Contains(out, "\x1b[1m users-db") {
+		t.Errorf("data row drawn bold:\n%q", out)
+	}
+}
+
+func TestGroupErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		groupBy   string
+		subtotals []string
+		want      string
+	}{
+		{"unknown group column", "Region", nil, `group_by: unknown column "Region"`},
+		{"unknown subtotal column", "Status", []string{"Load"}, `subtotals: unknown column "Load"`},
+		{"unknown aggregate", "Status", []string{"Connections:median"}, `subtotals: unknown aggregation "median"`},
+		{"subtotals without group", "", []string{"Connections"}, "subtotals: group_by is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := groupConfig
+			config.GroupBy, config.Subtotals = tt.groupBy, tt.subtotals
+			_, err := RenderString(testRenderer(t), config)
+			if err == nil {
+				t.Fatal("render succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
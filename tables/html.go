@@ -0,0 +1,103 @@
+package tables
+
+import (
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// HTMLRenderer renders tables as an HTML <table>. Cell styles become inline
+// CSS and **bold** text becomes <strong>.
+type HTMLRenderer struct{}
+
+var htmlBoldPattern = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+
+// htmlText escapes cell text and converts bold markers
+func htmlText(text string) string {
+	return htmlBoldPattern.ReplaceAllString(html.EscapeString(text), "<strong>$1</strong>")
+}
+
+// htmlAlign returns the CSS text-align value for an alignment. Columns
+// aligned on a separator are right-aligned.
+func htmlAlign(alignment AlignmentType) string {
+	switch alignment {
+	case AlignCenter:
+		return "center"
+	case AlignRight:
+		return "right"
+	}
+	if _, ok := alignment.separator(); ok {
+		return "right"
+	}
+	return "left"
+}
+
+// htmlCell writes a single th or td element
+func htmlCell(tw *tableWriter, tag, text string, alignment AlignmentType, style *CellStyle, colspan int) {
+	tw.WriteString("<" + tag)
+	if colspan > 1 {
+		tw.WriteString(` colspan="` + strconv.Itoa(colspan) + `"`)
+	}
+	css := "text-align:" + htmlAlign(alignment)
+	if style != nil {
+		if extra := style.css(); extra != "" {
+			css += ";" + extra
+		}
+	}
+	tw.WriteString(` style="` + css + `">` + htmlText(text) + "</" + tag + ">")
+}
+
+// Render writes the table as HTML
+func (r *HTMLRenderer) Render(w io.Writer, config TableConfig) error {
+	if len(config.Headers) == 0 {
+		return ErrNoHeaders
+	}
+
+	config, err := Prepare(config)
+	if err != nil {
+		return err
+	}
+
+	tw := &tableWriter{w: w}
+	columns := len(config.Headers)
+
+	tw.WriteString("<table>\n")
+	if config.Name != "" {
+		tw.WriteString("  <caption>" + htmlText(config.Name) + "</caption>\n")
+	}
+
+	tw.WriteString("  <thead>\n    <tr>")
+	for i, header := range config.Headers {
+		htmlCell(tw, "th", header, getHeaderAlignment(config, i), nil, 1)
+	}
+	tw.WriteString("</tr>\n  </thead>\n  <tbody>\n")
+
+	if len(config.Rows) == 0 && config.EmptyText != "" {
+		tw.WriteString(`    <tr class="empty">`)
+		htmlCell(tw, "td", config.EmptyText, AlignCenter, nil, columns)
+		tw.WriteString("</tr>\n")
+	}
+
+	for _, row := range config.Rows {
+		if row.Section != "" {
+			tw.WriteString(`    <tr class="section">`)
+			htmlCell(tw, "th", row.Section, parseAlignment(row.Align), nil, columns)
+			tw.WriteString("</tr>\n")
+			continue
+		}
+
+		tw.WriteString("    <tr>")
+		for i := 0; i < columns; i++ {
+			var style *CellStyle
+			if i < len(row.Cells) {
+				style = row.Cells[i].Style
+			}
+			htmlCell(tw, "td", row.Text(i), getCellAlignment(config, row, i), style, 1)
+		}
+		tw.WriteString("</tr>\n")
+	}
+
+	tw.WriteString("  </tbody>\n</table>\n")
+	return tw.err
+}
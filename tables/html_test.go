@@ -0,0 +1,89 @@
+package tables
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func renderHTML(t *testing.T, config TableConfig) string {
+	t.Helper()
+	out, err := RenderString(&HTMLRenderer{}, config)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return out
+}
+
+func TestHTMLRender(t *testing.T) {
+	config := TableConfig{
+		Name:      "Status <live>",
+		Headers:   []string{"Database", "Load"},
+		Alignment: []string{"left", "decimal"},
+		Rows: []Row{
+			{Section: "Primary"},
+			TextRow("**users-db**", "0.5"),
+			{Cells: []Cell{{Text: "a & b", Align: "center"}, {Text: "0.75", Style: &CellStyle{Color: "red", Bold: true}}}},
+		},
+	}
+
+	want := `<table>
+  <caption>Status &lt;live&gt;</caption>
+  <thead>
+    <tr><th style="text-align:left">Database</th><th style="text-align:right">Load</th></tr>
+  </thead>
+  <tbody>
+    <tr class="section"><th colspan="2" style="text-align:left">Primary</th></tr>
+    <tr><td style="text-align:left"><strong>users-db</strong></td><td style="text-align:right">0.5</td></tr>
+    <tr><td style="text-align:center">a &amp; b</td><td style="text-align:right;color:#cd3131;font-weight:bold">0.75</td></tr>
+  </tbody>
+</table>
+`
+	if got := renderHTML(t, config); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHTMLRules(t *testing.T) {
+	config := TableConfig{
+		Headers: []string{"Database", "Status"},
+		Rows:    TextRows([][]string{{"users-db", "Online"}, {"orders-db", "Offline"}}),
+		Rules: []Rule{
+			{When: `Status == "Offline"`, CellStyle: CellStyle{Background: "rgba(255, 0, 0, 0.5)", Italic: true}},
+			{When: `Status == "Offline"`, Column: "Status", CellStyle: CellStyle{Underline: true}},
+		},
+	}
+
+	out := renderHTML(t, config)
+	for _, want := range []string{
+		`<td style="text-align:left">users-db</td>`,
+		`<td style="text-align:left;background-color:#ff000080;font-style:italic">orders-db</td>`,
+		`<td style="text-align:left;background-color:#ff000080;font-style:italic;text-decoration:underline">Offline</td>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTMLEmpty(t *testing.T) {
+	out := renderHTML(t, TableConfig{Headers: []string{"A", "B"}, EmptyText: "no <rows>"})
+	want := `    <tr class="empty"><td colspan="2" style="text-align:center">no &lt;rows&gt;</td></tr>`
+	if !strings.Contains(out, want) {
+		t.Errorf("output has no %q:\n%s", want, out)
+	}
+
+	if _, err := RenderString(&HTMLRenderer{}, TableConfig{}); !errors.Is(err, ErrNoHeaders) {
+		t.Errorf("error = %v, want ErrNoHeaders", err)
+	}
+}
+
+func TestHTMLRegistered(t *testing.T) {
+	renderer, err := GetRenderer("HTML")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := renderer.(*HTMLRenderer); !ok {
+		t.Errorf("GetRenderer(\"HTML\") = %T, want *HTMLRenderer", renderer)
+	}
+}
@@ -0,0 +1,229 @@
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotStreamable is returned when a configuration needs every row before drawing
+var ErrNotStreamable = errors.New("option requires all rows and cannot be streamed")
+
+// pipeline holds the compiled data options of a table configuration
+type pipeline struct {
+	computed   []computedColumn
+	sources    int // number of columns before computed columns are added
+	width      int // number of columns including computed columns
+	projection *projection
+	formats    []numberFormat
+	sortKeys   []sortKey
+	filter     exprNode
+	rules      []rule
+	grouping   *grouping
+	transpose  bool
+}
+
+// columnIndex finds a column by header name. Matching ignores case, bold
+// markers and surrounding spaces.
+func columnIndex(headers []string, name string) (int, error) {
+	name = strings.TrimSpace(cleanText(name))
+	for i, header := range headers {
+		if strings.EqualFold(strings.TrimSpace(cleanText(header)), name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown column %q", name)
+}
+
+// newPipeline compiles the data options of a configuration
+func newPipeline(config TableConfig) (*pipeline, error) {
+	sources := len(config.Headers)
+	config, computed, err := withComputedColumns(config)
+	if err != nil {
+		return nil, err
+	}
+
+	projection, err := newProjection(config)
+	if err != nil {
+		return nil, err
+	}
+	p := &pipeline{
+		computed:   computed,
+		sources:    sources,
+		width:      len(config.Headers),
+		projection: projection,
+		transpose:  config.Transpose,
+	}
+
+	// Formats belong to the output columns
+	for i, spec := range projection.formats {
+		f, err := parseFormat(spec)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", projection.headers[i], err)
+		}
+		p.formats = append(p.formats, f)
+	}
+
+	if strings.TrimSpace(config.Filter) != "" {
+		filter, err := compileExpr(config.Filter, config.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("filter: %v", err)
+		}
+		p.filter = filter
+	}
+
+	if len(config.Rules) > 0 {
+		rules, err := compileRules(config.Rules, config.Headers)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = rules
+	}
+
+	if config.Sort != "" {
+		keys, err := parseSortKeys(config.Sort, config.Headers)
+		if err != nil {
+			return nil, err
+		}
+		p.sortKeys = keys
+	}
+
+	switch {
+	case strings.TrimSpace(config.GroupBy) != "":
+		grouping, err := newGrouping(config, projection.sources)
+		if err != nil {
+			return nil, err
+		}
+		p.grouping = grouping
+	case len(config.Subtotals) > 0:
+		return nil, fmt.Errorf("subtotals: group_by is not set")
+	}
+
+	return p, nil
+}
+
+// streamable reports an error for options that need all rows at once
+func (p *pipeline) streamable() error {
+	if len(p.sortKeys) > 0 {
+		return fmt.Errorf("sort: %w", ErrNotStreamable)
+	}
+	if p.grouping != nil {
+		return fmt.Errorf("group_by: %w", ErrNotStreamable)
+	}
+	if p.transpose {
+		return fmt.Errorf("transpose: %w", ErrNotStreamable)
+	}
+	return nil
+}
+
+// compute appends the computed columns to a row and applies the rules
+func (p *pipeline) compute(row Row) (Row, error) {
+	row, err := computeRow(p.computed, row, p.sources)
+	if err != nil {
+		return row, err
+	}
+	return applyRules(p.rules, row, p.width)
+}
+
+// keep reports whether a row passes the filter. Section rows are always kept.
+func (p *pipeline) keep(row Row) (bool, error) {
+	if p.filter == nil || row.Section != "" {
+		return true, nil
+	}
+	v, err := p.filter.eval(row)
+	if err != nil {
+		return false, fmt.Errorf("filter: %v", err)
+	}
+	return truthy(v), nil
+}
+
+// columns returns the configuration with its output columns and without
+// the options already handled by the pipeline
+func (p *pipeline) columns(config TableConfig) TableConfig {
+	config = p.projection.apply(config)
+	config.Filter = ""
+	config.Sort = ""
+	config.GroupBy = ""
+	config.Subtotals = nil
+	config.ComputedColumns = nil
+	config.Rules = nil
+	return config
+}
+
+// row applies the per-row options to a single data row: column selection,
+// then formats. Section rows pass through unchanged.
+func (p *pipeline) row(row Row) Row {
+	if row.Section != "" {
+		return row
+	}
+	row = p.projection.row(row)
+	if len(p.formats) == 0 {
+		return row
+	}
+
+	texts := row.Texts()
+	for i, text := range texts {
+		if i < len(p.formats) {
+			texts[i] = p.formats[i].apply(text)
+		}
+	}
+	return row.withTexts(texts)
+}
+
+// Prepare applies the data options of a configuration (pivoting, computed
+// columns, filtering, sorting, grouping, column selection, column formats, then transposing)
+// and returns a configuration ready for drawing. Renderers call it before
+// laying out a table; custom renderers registered in a Registry should do
+// the same.
+func Prepare(config TableConfig) (TableConfig, error) {
+	if config.Pivot != nil {
+		pivoted, err := pivotTable(config)
+		if err != nil {
+			return config, err
+		}
+		config = pivoted
+	}
+
+	p, err := newPipeline(config)
+	if err != nil {
+		return config, err
+	}
+
+	rows := make([]Row, 0, len(config.Rows))
+	for _, row := range config.Rows {
+		row, err := p.compute(row)
+		if err != nil {
+			return config, err
+		}
+		keep, err := p.keep(row)
+		if err != nil {
+			return config, err
+		}
+		if keep {
+			rows = append(rows, row)
+		}
+	}
+
+	// Sort on the raw values, before formatting changes them
+	if len(p.sortKeys) > 0 {
+		sortRows(rows, p.sortKeys)
+	}
+
+	// Group on the source columns so the group column may be hidden
+	if p.grouping != nil {
+		rows = p.grouping.apply(rows, p.width)
+	}
+
+	for i, row := range rows {
+		rows[i] = p.row(row)
+	}
+
+	config = p.columns(config)
+	config.Rows = rows
+
+	if p.transpose {
+		config = transposeTable(config)
+	}
+
+	return config, nil
+}
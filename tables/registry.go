@@ -0,0 +1,98 @@
+package tables
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RendererFactory creates a renderer for a registered table type
+type RendererFactory func() TableRenderer
+
+// Registry maps table type names to renderer factories. It is safe for
+// concurrent use. Names are case-insensitive.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]RendererFactory
+}
+
+// DefaultRegistry holds the predefined table styles and is used by the
+// package-level GetRenderer, RegisterTableStyle and RegisterRenderer
+var DefaultRegistry = newDefaultRegistry()
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]RendererFactory)}
+}
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for name, style := range tableStyles {
+		r.RegisterStyle(name, style)
+	}
+	r.Register("html", func() TableRenderer {
+		return &HTMLRenderer{}
+	})
+	return r
+}
+
+// Register adds a renderer factory under name, replacing any existing
+// registration. It reports whether a previous registration was replaced.
+func (r *Registry) Register(name string, factory RendererFactory) bool {
+	if factory == nil {
+		panic("tables: Register factory is nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(name)
+	_, replaced := r.factories[key]
+	r.factories[key] = factory
+	return replaced
+}
+
+// RegisterStyle registers an ASCII renderer using the given border style
+func (r *Registry) RegisterStyle(name string, style TableStyle) bool {
+	return r.Register(name, func() TableRenderer {
+		return &ASCIITableRenderer{Style: style}
+	})
+}
+
+// Unregister removes a table type, reporting whether it was registered
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(name)
+	_, exists := r.factories[key]
+	delete(r.factories, key)
+	return exists
+}
+
+// Get returns a new renderer for the table type
+func (r *Registry) Get(tableType string) (TableRenderer, error) {
+	r.mu.RLock()
+	factory, exists := r.factories[strings.ToLower(tableType)]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown table type: %s. Available types: %v",
+			tableType, r.Types())
+	}
+	return factory(), nil
+}
+
+// Types returns the registered table type names in sorted order
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.factories))
+	for typeName := range r.factories {
+		types = append(types, typeName)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,72 @@
+package tables
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Run with -race to check the registry locking
+func TestRegistryConcurrent(t *testing.T) {
+	const workers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		name := fmt.Sprintf("test-style-%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				RegisterRenderer(name, func() TableRenderer {
+					return &ASCIITableRenderer{Style: tableStyles["single-line-full"]}
+				})
+				GetAvailableTypes()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := GetRenderer("single-line-full"); err != nil {
+					t.Error(err)
+					return
+				}
+				GetRenderer(name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		name := fmt.Sprintf("test-style-%d", i)
+		if _, err := GetRenderer(name); err != nil {
+			t.Errorf("GetRenderer(%q): %v", name, err)
+		}
+		if !UnregisterRenderer(name) {
+			t.Errorf("UnregisterRenderer(%q) = false, want true", name)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	factory := func() TableRenderer { return &HTMLRenderer{} }
+
+	if r.Register("Custom", factory) {
+		t.Error("first Register reported a replacement")
+	}
+	if !r.Register("custom", factory) {
+		t.Error("second Register did not report a replacement")
+	}
+	if _, err := r.Get("CUSTOM"); err != nil {
+		t.Errorf("Get is not case-insensitive: %v", err)
+	}
+	if got := r.Types(); len(got) != 1 || got[0] != "custom" {
+		t.Errorf("Types() = %q, want [custom]", got)
+	}
+
+	if !r.Unregister("custom") {
+		t.Error("Unregister = false, want true")
+	}
+	if _, err := r.Get("custom"); err == nil {
+		t.Error("Get after Unregister succeeded")
+	}
+}
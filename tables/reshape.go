@@ -0,0 +1,133 @@
+package tables
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PivotSpec turns a long-format table of (row, column, value) records into
+// a cross-tab with one row per Rows value and one column per Columns value.
+// Cells combine every matching value with Aggregate (sum by default).
+type PivotSpec struct {
+	Rows      string `json:"rows"`
+	Columns   string `json:"columns"`
+	Values    string `json:"values"`
+	Aggregate string `json:"aggregate,omitempty"`
+}
+
+// pivotTable reshapes a configuration according to its Pivot spec. Rows and
+// columns keep the order their values first appear in; the new value
+// columns take the alignment and format of the Values column.
+func pivotTable(config TableConfig) (TableConfig, error) {
+	spec := config.Pivot
+	rowCol, err := columnIndex(config.Headers, spec.Rows)
+	if err != nil {
+		return config, fmt.Errorf("pivot rows: %v", err)
+	}
+	colCol, err := columnIndex(config.Headers, spec.Columns)
+	if err != nil {
+		return config, fmt.Errorf("pivot columns: %v", err)
+	}
+	valCol, err := columnIndex(config.Headers, spec.Values)
+	if err != nil {
+		return config, fmt.Errorf("pivot values: %v", err)
+	}
+	agg, err := parseAggregate(spec.Aggregate)
+	if err != nil {
+		return config, fmt.Errorf("pivot: %v", err)
+	}
+
+	var rowKeys, colKeys []string
+	rowSeen, colSeen := map[string]bool{}, map[string]bool{}
+	values := map[[2]string][]string{}
+	for _, row := range config.Rows {
+		if row.Section != "" {
+			continue
+		}
+		r := strings.TrimSpace(row.Text(rowCol))
+		c := strings.TrimSpace(row.Text(colCol))
+		if !rowSeen[r] {
+			rowSeen[r] = true
+			rowKeys = append(rowKeys, r)
+		}
+		if !colSeen[c] {
+			colSeen[c] = true
+			colKeys = append(colKeys, c)
+		}
+		key := [2]string{r, c}
+		values[key] = append(values[key], row.Text(valCol))
+	}
+
+	at := func(list []string, i int) string {
+		if i < len(list) {
+			return list[i]
+		}
+		return ""
+	}
+
+	pivoted := config
+	pivoted.Pivot = nil
+	pivoted.Headers = append([]string{config.Headers[rowCol]}, colKeys...)
+	pivoted.Alignment = []string{at(config.Alignment, rowCol)}
+	pivoted.HeaderAlignment = []string{at(config.HeaderAlignment, rowCol)}
+	pivoted.Format = []string{at(config.Format, rowCol)}
+	for range colKeys {
+		pivoted.Alignment = append(pivoted.Alignment, at(config.Alignment, valCol))
+		pivoted.HeaderAlignment = append(pivoted.HeaderAlignment, at(config.HeaderAlignment, valCol))
+		pivoted.Format = append(pivoted.Format, at(config.Format, valCol))
+	}
+
+	pivoted.Rows = make([]Row, len(rowKeys))
+	for i, r := range rowKeys {
+		texts := []string{r}
+		for _, c := range colKeys {
+			cell := ""
+			if matches, ok := values[[2]string{r, c}]; ok {
+				cell = aggregate(agg, matches)
+			}
+			texts = append(texts, cell)
+		}
+		pivoted.Rows[i] = TextRow(texts...)
+	}
+
+	return pivoted, nil
+}
+
+// transposeTable renders each record vertically as a section row followed
+// by one "Field | Value" row per column, like psql's expanded mode. Values
+// keep the alignment they had in the original table, except that
+// separator alignments become right alignment since fields no longer share
+// a column.
+func transposeTable(config TableConfig) TableConfig {
+	transposed := config
+	transposed.Transpose = false
+	transposed.Headers = []string{"Field", "Value"}
+	transposed.Alignment = nil
+	transposed.HeaderAlignment = nil
+	transposed.Rows = make([]Row, 0, len(config.Rows)*(len(config.Headers)+1))
+
+	record := 0
+	for _, row := range config.Rows {
+		if row.Section != "" {
+			transposed.Rows = append(transposed.Rows, row)
+			continue
+		}
+
+		record++
+		transposed.Rows = append(transposed.Rows, Row{Section: "Record " + strconv.Itoa(record)})
+		for i, header := range config.Headers {
+			align := getCellAlignment(config, row, i)
+			if _, ok := align.separator(); ok {
+				align = AlignRight
+			}
+			value := Cell{Text: row.Text(i), Align: string(align)}
+			if i < len(row.Cells) {
+				value.Style = row.Cells[i].Style
+			}
+			transposed.Rows = append(transposed.Rows, Row{Cells: []Cell{{Text: header}, value}})
+		}
+	}
+
+	return transposed
+}
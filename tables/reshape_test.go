@@ -0,0 +1,182 @@
+package tables
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// pivotConfig is the long-format example of the readme
+var pivotConfig = TableConfig{
+	Headers:   []string{"Database", "Metric", "Value"},
+	Alignment: []string{"left", "left", "decimal"},
+	Format:    []string{"", "", "number:1"},
+	Rows: TextRows([][]string{
+		{"users-db", "cpu", "12.5"}, {"users-db", "mem", "40"},
+		{"orders-db", "cpu", "3.25"}, {"users-db", "cpu", "1"}, {"orders-db", "disk", "70"},
+	}),
+}
+
+func TestPivotRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		aggregate string
+		sort      string
+		want      []string
+	}{
+		{
+			name: "sum of duplicate keys",
+			sort: "cpu:desc",
+			want: []string{
+				"│ Database  │  cpu │  mem │ disk │",
+				"│ users-db  │ 13.5 │ 40.0 │      │",
+				"│ orders-db │  3.2 │      │ 70.0 │",
+			},
+		},
+		{
+			name:      "count",
+			aggregate: "count",
+			want:      []string{"│ users-db  │ 2.0 │ 1.0 │      │", "│ orders-db │ 1.0 │     │  1.0 │"},
+		},
+		{
+			name:      "average",
+			aggregate: "avg",
+			want:      []string{"│ users-db  │ 6.8 │ 40.0 │      │"},
+		},
+		{
+			name:      "min",
+			aggregate: "min",
+			want:      []string{"│ users-db  │ 1.0 │ 40.0 │      │"},
+		},
+		{
+			name:      "max",
+			aggregate: "MAX",
+			want:      []string{"│ users-db  │ 12.5 │ 40.0 │      │"},
+		},
+		{
+			name:      "first",
+			aggregate: "first",
+			want:      []string{"│ users-db  │ 12.5 │ 40.0 │      │"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := pivotConfig
+			config.Pivot = &PivotSpec{Rows: "Database", Columns: "Metric", Values: "Value", Aggregate: tt.aggregate}
+			config.Sort = tt.sort
+			out := renderTable(t, config)
+			for _, line := range tt.want {
+				if !strings.Contains(out, line) {
+					t.Errorf("output has no line containing %q:\n%s", line, out)
+				}
+			}
+		})
+	}
+}
+
+func TestPivotThenColumns(t *testing.T) {
+	config := pivotConfig
+	config.Pivot = &PivotSpec{Rows: "Database", Columns: "Metric", Values: "Value"}
+	config.Columns = []ColumnSpec{{Name: "Database"}, {Name: "disk", Header: "Disk"}}
+	config.Filter = "disk > 0"
+
+	out := renderTable(t, config)
+	for _, line := range []string{"│ Database  │ Disk │", "│ orders-db │ 70.0 │"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output has no line containing %q:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "users-db") {
+		t.Errorf("filtered row rendered:\n%s", out)
+	}
+}
+
+func TestPivotErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec PivotSpec
+		want string
+	}{
+		{"unknown rows column", PivotSpec{Rows: "Host", Columns: "Metric", Values: "Value"}, `pivot rows: unknown column "Host"`},
+		{"unknown columns column", PivotSpec{Rows: "Database", Columns: "Kind", Values: "Value"}, `pivot columns: unknown column "Kind"`},
+		{"unknown values column", PivotSpec{Rows: "Database", Columns: "Metric", Values: "Amount"}, `pivot values: unknown column "Amount"`},
+		{"unknown aggregate", PivotSpec{Rows: "Database", Columns: "Metric", Values: "Value", Aggregate: "median"}, `pivot: unknown aggregation "median"`},
+	}
+
+	renderer, err := GetRenderer("single-line-full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := pivotConfig
+			config.Pivot = &tt.spec
+			_, err := RenderString(renderer, config)
+			if err == nil {
+				t.Fatal("render succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPivotNotStreamable(t *testing.T) {
+	config := TableConfig{Pivot: &PivotSpec{Rows: "A", Columns: "B", Values: "C"}}
+	reader := NewCSVReader(strings.NewReader("A,B,C\nx,y,1\n"), nil)
+	err := testRenderer(t).Stream(&strings.Builder{}, config, reader, StreamOptions{})
+	if !errors.Is(err, ErrNotStreamable) {
+		t.Errorf("Stream error = %v, want ErrNotStreamable", err)
+	}
+}
+
+func TestTransposeRender(t *testing.T) {
+	config := TableConfig{
+		Headers:   []string{"Database", "Load", "Status"},
+		Alignment: []string{"left", "decimal", "center"},
+		Rows: TextRows([][]string{
+			{"Primary", "0.75", "Online"},
+			{"Replica", "12.5", "Offline"},
+		}),
+		Columns:   []ColumnSpec{{Name: "Database", Header: "DB"}, {Name: "Load"}},
+		Sort:      "Load:desc",
+		Transpose: true,
+	}
+
+	out := renderTable(t, config)
+	want := []string{
+		"│ Field │ Value   │",
+		"│ Record 1        │",
+		"│ DB    │ Replica │",
+		"│ Load  │    12.5 │",
+		"│ Record 2        │",
+		"│ DB    │ Primary │",
+		"│ Load  │    0.75 │",
+	}
+	last := -1
+	for _, line := range want {
+		i := strings.Index(out, line)
+		if i < 0 {
+			t.Errorf("output has no line containing %q:\n%s", line, out)
+			continue
+		}
+		if i < last {
+			t.Errorf("line %q out of order:\n%s", line, out)
+		}
+		last = i
+	}
+	if strings.Contains(out, "Status") {
+		t.Errorf("unselected column transposed:\n%s", out)
+	}
+}
+
+func TestTransposeEmpty(t *testing.T) {
+	out := renderTable(t, TableConfig{Headers: []string{"A", "B"}, Transpose: true, EmptyText: "no rows"})
+	for _, line := range []string{"│ Field │ Value │", "no rows"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output has no line containing %q:\n%s", line, out)
+		}
+	}
+}
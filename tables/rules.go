@@ -0,0 +1,195 @@
+package tables
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tablemaker/output"
+)
+
+// CellStyle is the color and emphasis of a cell. Colors are names ("red",
+// "bright-blue", "orange") or hex values ("#ff8800").
+type CellStyle struct {
+	Color      string `json:"color,omitempty"`
+	Background string `json:"background,omitempty"`
+	Bold       bool   `json:"bold,omitempty"`
+	Italic     bool   `json:"italic,omitempty"`
+	Underline  bool   `json:"underline,omitempty"`
+}
+
+// validate checks that the colors of a style parse
+func (s CellStyle) validate() error {
+	for _, c := range []string{s.Color, s.Background} {
+		if c == "" {
+			continue
+		}
+		if _, err := output.ParseColor(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge returns a style with the options set in other applied over s
+func (s *CellStyle) merge(other CellStyle) *CellStyle {
+	merged := other
+	if s != nil {
+		merged = *s
+		if other.Color != "" {
+			merged.Color = other.Color
+		}
+		if other.Background != "" {
+			merged.Background = other.Background
+		}
+		merged.Bold = merged.Bold || other.Bold
+		merged.Italic = merged.Italic || other.Italic
+		merged.Underline = merged.Underline || other.Underline
+	}
+	return &merged
+}
+
+// sgr returns the ANSI escape sequence selecting the style. Named ANSI
+// colors use the terminal palette; other colors use 24-bit color.
+func (s *CellStyle) sgr() string {
+	var codes []string
+	if s.Bold {
+		codes = append(codes, "1")
+	}
+	if s.Italic {
+		codes = append(codes, "3")
+	}
+	if s.Underline {
+		codes = append(codes, "4")
+	}
+	if s.Color != "" {
+		codes = append(codes, sgrColor(s.Color, 30, 90, 38))
+	}
+	if s.Background != "" {
+		codes = append(codes, sgrColor(s.Background, 40, 100, 48))
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// sgrColor returns the SGR parameters for a color given the bases of the
+// normal, bright and extended color codes
+func sgrColor(name string, normal, bright, extended int) string {
+	if i, ok := output.ANSIColorIndex(name); ok {
+		if i < 8 {
+			return strconv.Itoa(normal + i)
+		}
+		return strconv.Itoa(bright + i - 8)
+	}
+	c, _ := output.ParseColor(name)
+	return fmt.Sprintf("%d;2;%d;%d;%d", extended, c.R, c.G, c.B)
+}
+
+// textStyle converts the style for drawing as an image
+func (s *CellStyle) textStyle() output.TextStyle {
+	style := output.TextStyle{Bold: s.Bold, Italic: s.Italic, Underline: s.Underline}
+	if c, err := output.ParseColor(s.Color); err == nil && s.Color != "" {
+		style.Color = &c
+	}
+	if c, err := output.ParseColor(s.Background); err == nil && s.Background != "" {
+		style.Background = &c
+	}
+	return style
+}
+
+// css returns the style as inline CSS declarations
+func (s *CellStyle) css() string {
+	var decls []string
+	if s.Color != "" {
+		c, _ := output.ParseColor(s.Color)
+		decls = append(decls, "color:"+output.HexColor(c))
+	}
+	if s.Background != "" {
+		c, _ := output.ParseColor(s.Background)
+		decls = append(decls, "background-color:"+output.HexColor(c))
+	}
+	if s.Bold {
+		decls = append(decls, "font-weight:bold")
+	}
+	if s.Italic {
+		decls = append(decls, "font-style:italic")
+	}
+	if s.Underline {
+		decls = append(decls, "text-decoration:underline")
+	}
+	return strings.Join(decls, ";")
+}
+
+// Rule styles cells of the rows matching an expression, e.g.
+// {"when": "Status == \"Offline\"", "column": "Status", "color": "red"}.
+// Without a column the whole row is styled. Later rules override the
+// colors of earlier ones.
+type Rule struct {
+	When   string `json:"when"`
+	Column string `json:"column,omitempty"`
+	CellStyle
+}
+
+// rule is a compiled Rule
+type rule struct {
+	when   exprNode
+	column int // -1 styles the whole row
+	style  CellStyle
+}
+
+// compileRules compiles rules against the table headers
+func compileRules(rules []Rule, headers []string) ([]rule, error) {
+	var compiled []rule
+	for i, r := range rules {
+		when, err := compileExpr(r.When, headers)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i+1, err)
+		}
+		if err := r.CellStyle.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i+1, err)
+		}
+
+		column := -1
+		if r.Column != "" {
+			if column, err = columnIndex(headers, r.Column); err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i+1, err)
+			}
+		}
+		compiled = append(compiled, rule{when: when, column: column, style: r.CellStyle})
+	}
+	return compiled, nil
+}
+
+// applyRules styles the cells of a row with the given number of columns
+// for every matching rule. The row's cells are copied before styling.
+func applyRules(rules []rule, row Row, width int) (Row, error) {
+	if len(rules) == 0 || row.Section != "" {
+		return row, nil
+	}
+
+	copied := false
+	for i, r := range rules {
+		v, err := r.when.eval(row)
+		if err != nil {
+			return row, fmt.Errorf("rule %d: %v", i+1, err)
+		}
+		if !truthy(v) {
+			continue
+		}
+
+		if !copied {
+			cells := make([]Cell, max(width, len(row.Cells)))
+			copy(cells, row.Cells)
+			row.Cells = cells
+			copied = true
+		}
+		for col := range row.Cells {
+			if r.column < 0 || r.column == col {
+				row.Cells[col].Style = row.Cells[col].Style.merge(r.style)
+			}
+		}
+	}
+	return row, nil
+}
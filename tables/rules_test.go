@@ -0,0 +1,104 @@
+package tables
+
+import (
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tablemaker/output"
+)
+
+var rulesConfig = TableConfig{
+	Headers: []string{"Database", "Status"},
+	Rows:    TextRows([][]string{{"users-db", "Online"}, {"orders-db", "Offline"}}),
+	Rules: []Rule{
+		{When: `Status == "Offline"`, Column: "Status", CellStyle: CellStyle{Color: "red", Bold: true}},
+		{When: `Database =~ "^orders"`, CellStyle: CellStyle{Background: "rgba(0, 0, 255, 0.5)", Italic: true}},
+	},
+}
+
+func TestRulesANSI(t *testing.T) {
+	renderer := &ASCIITableRenderer{Style: tableStyles["single-line-full"], ANSI: true}
+	out, err := RenderString(renderer, rulesConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "│\x1b[3;48;2;0;0;128m orders-db \x1b[0m│\x1b[1;3;31;48;2;0;0;128m Offline \x1b[0m│"
+	if !strings.Contains(out, want) {
+		t.Errorf("output has no line containing %q:\n%q", want, out)
+	}
+	if !strings.Contains(out, "│ users-db  │ Online  │") {
+		t.Errorf("unmatched row styled:\n%q", out)
+	}
+}
+
+func TestRenderStyled(t *testing.T) {
+	renderer := &ASCIITableRenderer{Style: tableStyles["single-line-full"], ANSI: true}
+	text, spans, err := RenderStyledString(renderer, rulesConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(text, "\x1b") {
+		t.Errorf("styled text has escape sequences:\n%q", text)
+	}
+	if !renderer.ANSI {
+		t.Error("RenderStyled changed the renderer")
+	}
+
+	blue := color.RGBA{0, 0, 128, 128}
+	red := color.RGBA{205, 49, 49, 255}
+	want := []output.Span{
+		{Line: 5, Start: 1, End: 12, Style: output.TextStyle{Background: &blue, Italic: true}},
+		{Line: 5, Start: 13, End: 22, Style: output.TextStyle{Color: &red, Background: &blue, Bold: true, Italic: true}},
+	}
+	if !reflect.DeepEqual(spans, want) {
+		t.Errorf("spans = %+v, want %+v", spans, want)
+	}
+
+	// The spans cover the cells of the styled row
+	line := []rune(strings.Split(text, "\n")[5])
+	if got := string(line[1:12]); got != " orders-db " {
+		t.Errorf("first span covers %q", got)
+	}
+	if got := string(line[13:22]); got != " Offline " {
+		t.Errorf("second span covers %q", got)
+	}
+}
+
+func TestRenderStyledUnstyled(t *testing.T) {
+	text, spans, err := RenderStyledString(&HTMLRenderer{}, rulesConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spans != nil || !strings.HasPrefix(text, "<table>") {
+		t.Errorf("RenderStyledString(HTMLRenderer) = %q, %v", text, spans)
+	}
+}
+
+func TestRuleErrors(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{When: "Load > 1"}, `rule 1: unknown column "Load"`},
+		{Rule{When: "Status", Column: "Load"}, `rule 1: unknown column "Load"`},
+		{Rule{When: "Status", CellStyle: CellStyle{Color: "#12"}}, `rule 1: invalid color "#12"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			config := rulesConfig
+			config.Rules = []Rule{tt.rule}
+			_, err := RenderString(testRenderer(t), config)
+			if err == nil {
+				t.Fatal("render succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
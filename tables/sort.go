@@ -0,0 +1,285 @@
+package tables
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Sort value kinds used to compare a column
+const (
+	SortNumeric = "numeric"
+	SortNatural = "natural"
+	SortDate    = "date"
+	SortVersion = "version"
+)
+
+// sortKey is a parsed entry of a sort specification
+type sortKey struct {
+	column     int
+	descending bool
+	kind       string // empty detects the kind from the column values
+}
+
+// parseSortKeys parses a specification like "Connections:desc,Database"
+// against the table headers. Each key may carry "asc"/"desc" and a value
+// kind ("numeric", "natural", "date", "version") in any order.
+func parseSortKeys(spec string, headers []string) ([]sortKey, error) {
+	var keys []sortKey
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		parts := strings.Split(field, ":")
+		column, err := columnIndex(headers, parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("sort: %v", err)
+		}
+
+		key := sortKey{column: column}
+		for _, modifier := range parts[1:] {
+			switch m := strings.ToLower(strings.TrimSpace(modifier)); m {
+			case "asc":
+				key.descending = false
+			case "desc":
+				key.descending = true
+			case SortNumeric, SortNatural, SortDate, SortVersion:
+				key.kind = m
+			default:
+				return nil, fmt.Errorf("sort: unknown modifier %q for column %s", modifier, parts[0])
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// sortRows orders rows by the given keys, keeping the input order of equal rows
+func sortRows(rows []Row, keys []sortKey) {
+	for i := range keys {
+		if keys[i].kind == "" {
+			keys[i].kind = detectSortKind(rows, keys[i].column)
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, key := range keys {
+			c := compareValues(rows[i].Text(key.column), rows[j].Text(key.column), key.kind)
+			if c == 0 {
+				continue
+			}
+			if key.descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// detectSortKind picks the value kind that parses the most cells of a
+// column, provided it parses more than half of the non-empty cells; other
+// columns sort as natural text
+func detectSortKind(rows []Row, column int) string {
+	counts := map[string]int{}
+	values := 0
+	for _, row := range rows {
+		text := row.Text(column)
+		if strings.TrimSpace(cleanText(text)) == "" {
+			continue
+		}
+		values++
+		for _, kind := range []string{SortNumeric, SortDate, SortVersion} {
+			if _, ok := sortValue(text, kind); ok {
+				counts[kind]++
+			}
+		}
+	}
+
+	best := SortNatural
+	counts[best] = values / 2
+	for _, kind := range []string{SortNumeric, SortDate, SortVersion} {
+		if counts[kind] > counts[best] {
+			best = kind
+		}
+	}
+	return best
+}
+
+// compareValues compares two cells as the given kind. Empty cells sort
+// first, values of the kind sort before values that do not parse, and the
+// rest falls back to natural order.
+func compareValues(a, b, kind string) int {
+	a, b = strings.TrimSpace(cleanText(a)), strings.TrimSpace(cleanText(b))
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+
+	if kind != SortNatural {
+		va, okA := sortValue(a, kind)
+		vb, okB := sortValue(b, kind)
+		switch {
+		case okA && okB:
+			if c := compareParsed(va, vb, kind); c != 0 {
+				return c
+			}
+			return compareNatural(a, b)
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+	}
+
+	return compareNatural(a, b)
+}
+
+// dateLayouts are the layouts recognised when sorting dates
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"02 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	time.RFC1123,
+}
+
+var versionPattern = regexp.MustCompile(`^[vV]?\d+(\.\d+)+([-+][0-9A-Za-z.\-+]*)?$`)
+
+// sortValue parses cell text as the given kind
+func sortValue(text, kind string) (any, bool) {
+	text = strings.TrimSpace(cleanText(text))
+	switch kind {
+	case SortNumeric:
+		return parseNumber(text)
+	case SortDate:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t, true
+			}
+		}
+	case SortVersion:
+		if versionPattern.MatchString(text) {
+			return text, true
+		}
+	}
+	return nil, false
+}
+
+func compareParsed(a, b any, kind string) int {
+	switch kind {
+	case SortNumeric:
+		x, y := a.(float64), b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case SortDate:
+		return a.(time.Time).Compare(b.(time.Time))
+	case SortVersion:
+		return compareVersions(a.(string), b.(string))
+	}
+	return 0
+}
+
+// compareVersions compares dotted version strings numerically. A version
+// with a pre-release suffix ("1.2.0-rc1") sorts before the release itself.
+func compareVersions(a, b string) int {
+	a, b = strings.TrimLeft(a, "vV"), strings.TrimLeft(b, "vV")
+	coreA, preA, hasPreA := strings.Cut(strings.SplitN(a, "+", 2)[0], "-")
+	coreB, preB, hasPreB := strings.Cut(strings.SplitN(b, "+", 2)[0], "-")
+
+	partsA, partsB := strings.Split(coreA, "."), strings.Split(coreB, ".")
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case hasPreA && !hasPreB:
+		return -1
+	case !hasPreA && hasPreB:
+		return 1
+	}
+	return compareNatural(preA, preB)
+}
+
+// compareNatural compares text case-insensitively, treating runs of digits
+// as numbers so that "file10" sorts after "file2"
+func compareNatural(a, b string) int {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	i, j := 0, 0
+
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si := i
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			sj := j
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(na) != len(nb) {
+				if len(na) < len(nb) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		if ra[i] != rb[j] {
+			if ra[i] < rb[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+
+	switch {
+	case len(ra)-i < len(rb)-j:
+		return -1
+	case len(ra)-i > len(rb)-j:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,186 @@
+package tables
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectSortKind(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{"integers", []string{"10", "2", "-3"}, SortNumeric},
+		{"formatted numbers", []string{"$1,200", "45%", "12ms"}, SortNumeric},
+		{"dates", []string{"2024-01-02", "2023-12-31", "Jan 2, 2006"}, SortDate},
+		{"versions", []string{"1.2.3", "v1.10.0", "2.0.0-rc1"}, SortVersion},
+		{"two part versions are numbers", []string{"1.2", "1.10"}, SortNumeric},
+		{"text", []string{"alpha", "beta"}, SortNatural},
+		{"mostly numbers", []string{"1", "2", "N/A"}, SortNumeric},
+		{"mostly text", []string{"1", "N/A", "unknown"}, SortNatural},
+		{"empty cells ignored", []string{"", "3", " ", "**4**"}, SortNumeric},
+		{"no values", []string{"", ""}, SortNatural},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []Row
+			for _, v := range tt.values {
+				rows = append(rows, TextRow(v))
+			}
+			if got := detectSortKind(rows, 0); got != tt.want {
+				t.Errorf("detectSortKind(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"File2", "file2", -1}, // equal ignoring case, then byte order
+		{"a", "B", -1},
+		{"db-01", "db-1", -1}, // same number, then byte order
+		{"x007", "x7", -1},
+		{"abc", "abcd", -1},
+		{"10", "9", 1},
+		{"same", "same", 0},
+		{"", "a", -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareNatural(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareNatural(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.10.0", -1},
+		{"v2.0.0", "1.9.9", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0-rc1", "1.2.0", -1},
+		{"1.2.0", "1.2.0-rc1", 1},
+		{"1.2.0-rc2", "1.2.0-rc10", -1},
+		{"1.2.0+build5", "1.2.0", 0},
+		{"V3.1", "v3.1", 0},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareValuesMixed(t *testing.T) {
+	tests := []struct {
+		a, b, kind string
+		want       int
+	}{
+		{"", "1", SortNumeric, -1},
+		{"1", "", SortNumeric, 1},
+		{"", " ", SortNumeric, 0},
+		{"5", "N/A", SortNumeric, -1},
+		{"N/A", "5", SortNumeric, 1},
+		{"N/A", "n/b", SortNumeric, -1},
+		{"1,000", "999", SortNumeric, 1},
+		{"1.0", "1", SortNumeric, 1}, // equal numbers fall back to text
+		{"2024-01-02", "soon", SortDate, -1},
+		{"02 Jan 2024", "2023-12-31", SortDate, 1},
+		{"1.10.0", "1.9.0", SortVersion, 1},
+		{"1.10.0", "1.9.0", SortNatural, 1},
+		{"**2**", "10", SortNumeric, -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareValues(tt.a, tt.b, tt.kind); got != tt.want {
+			t.Errorf("compareValues(%q, %q, %s) = %d, want %d", tt.a, tt.b, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	headers := []string{"Name", "Status", "Load"}
+	rows := [][]string{
+		{"db-10", "Online", "45"},
+		{"db-2", "Offline", "N/A"},
+		{"db-1", "Online", "45"},
+		{"db-3", "Online", "7"},
+		{"db-4", "Offline", ""},
+	}
+
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"Name", []string{"db-1", "db-2", "db-3", "db-4", "db-10"}},
+		{"Name:desc", []string{"db-10", "db-4", "db-3", "db-2", "db-1"}},
+		{"Load", []string{"db-4", "db-3", "db-10", "db-1", "db-2"}},
+		{"Load:desc", []string{"db-2", "db-10", "db-1", "db-3", "db-4"}},
+		{"Status,Load:desc", []string{"db-2", "db-4", "db-10", "db-1", "db-3"}},
+		// Ties keep the input order
+		{"Status", []string{"db-2", "db-4", "db-10", "db-1", "db-3"}},
+		{"Status:desc", []string{"db-10", "db-1", "db-3", "db-2", "db-4"}},
+		{"Load:natural", []string{"db-4", "db-3", "db-10", "db-1", "db-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			keys, err := parseSortKeys(tt.spec, headers)
+			if err != nil {
+				t.Fatalf("parseSortKeys(%q): %v", tt.spec, err)
+			}
+			sorted := TextRows(rows)
+			sortRows(sorted, keys)
+
+			var got []string
+			for _, row := range sorted {
+				got = append(got, row.Text(0))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sort %q = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSortKeys(t *testing.T) {
+	headers := []string{"Name", "Version", "Released"}
+
+	keys, err := parseSortKeys(" Version:version:desc , Released:asc:date,Name ", headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sortKey{
+		{column: 1, descending: true, kind: SortVersion},
+		{column: 2, kind: SortDate},
+		{column: 0},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %+v, want %+v", keys, want)
+	}
+
+	errors := []struct {
+		spec, want string
+	}{
+		{"Missing", "sort: "},
+		{"Name:sideways", `unknown modifier "sideways"`},
+	}
+	for _, tt := range errors {
+		_, err := parseSortKeys(tt.spec, headers)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseSortKeys(%q) error = %v, want it to contain %q", tt.spec, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package tables
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultSampleSize is the number of rows buffered to size columns when streaming
+const DefaultSampleSize = 100
+
+// RowReader yields table rows one at a time
+type RowReader interface {
+	// Headers returns the column names, reading ahead if the input defines them
+	Headers() ([]string, error)
+	// Next returns the next row, or io.EOF once the input is exhausted
+	Next() ([]string, error)
+}
+
+// StreamOptions controls how a streamed table is sized
+type StreamOptions struct {
+	// SampleSize is the number of rows read ahead to size columns (defaults to DefaultSampleSize)
+	SampleSize int
+	// Widths fixes the content width of each column; zero entries are sized from the sample
+	Widths []int
+}
+
+// StreamRenderer is implemented by renderers that can write rows as they are read
+type StreamRenderer interface {
+	Stream(w io.Writer, config TableConfig, rows RowReader, opts StreamOptions) error
+}
+
+// csvRowReader reads rows from CSV input, taking headers from the first record
+type csvRowReader struct {
+	r       *csv.Reader
+	headers []string
+	read    bool
+}
+
+// NewCSVReader returns a RowReader for CSV input. When headers is nil the
+// first record is used as the header row.
+func NewCSVReader(r io.Reader, headers []string) RowReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &csvRowReader{r: cr, headers: headers, read: headers != nil}
+}
+
+func (c *csvRowReader) Headers() ([]string, error) {
+	if !c.read {
+		c.read = true
+		record, err := c.r.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV header: %v", err)
+		}
+		c.headers = record
+	}
+	return c.headers, nil
+}
+
+func (c *csvRowReader) Next() ([]string, error) {
+	if _, err := c.Headers(); err != nil {
+		return nil, err
+	}
+	record, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV row: %v", err)
+	}
+	return record, nil
+}
+
+// ndjsonRowReader reads one JSON array or object per line
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	headers []string
+	pending []string
+	read    bool
+	line    int
+}
+
+// NewNDJSONReader returns a RowReader for newline-delimited JSON. Each line
+// is either an array of values or an object. When headers is nil they are
+// taken from the first line: an array line is used as the header row, an
+// object line contributes its keys in order.
+func NewNDJSONReader(r io.Reader, headers []string) RowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &ndjsonRowReader{scanner: scanner, headers: headers, read: headers != nil}
+}
+
+func (n *ndjsonRowReader) Headers() ([]string, error) {
+	if n.read {
+		return n.headers, nil
+	}
+	n.read = true
+
+	line, err := n.nextLine()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if line[0] == '[' {
+		n.headers, err = parseJSONArray(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n.line, err)
+		}
+		return n.headers, nil
+	}
+
+	keys, values, err := parseJSONObject(line)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", n.line, err)
+	}
+	n.headers = keys
+	n.pending = values
+	return n.headers, nil
+}
+
+func (n *ndjsonRowReader) Next() ([]string, error) {
+	if _, err := n.Headers(); err != nil {
+		return nil, err
+	}
+	if n.pending != nil {
+		row := n.pending
+		n.pending = nil
+		return row, nil
+	}
+
+	line, err := n.nextLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if line[0] == '[' {
+		row, err := parseJSONArray(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n.line, err)
+		}
+		return row, nil
+	}
+
+	keys, values, err := parseJSONObject(line)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", n.line, err)
+	}
+
+	// Place object values by header name
+	row := make([]string, len(n.headers))
+	for i, key := range keys {
+		for j, header := range n.headers {
+			if header == key {
+				row[j] = values[i]
+				break
+			}
+		}
+	}
+	return row, nil
+}
+
+// nextLine returns the next non-blank line
+func (n *ndjsonRowReader) nextLine() ([]byte, error) {
+	for n.scanner.Scan() {
+		n.line++
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) > 0 {
+			return line, nil
+		}
+	}
+	if err := n.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read NDJSON: %v", err)
+	}
+	return nil, io.EOF
+}
+
+// parseJSONArray decodes a JSON array into cell strings
+func parseJSONArray(line []byte) ([]string, error) {
+	var values []json.RawMessage
+	if err := json.Unmarshal(line, &values); err != nil {
+		return nil, err
+	}
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = jsonCellText(value)
+	}
+	return row, nil
+}
+
+// parseJSONObject decodes a JSON object, keeping its keys in document order
+func parseJSONObject(line []byte) ([]string, []string, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, errors.New("expected a JSON array or object")
+	}
+
+	var keys, values []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, _ := tok.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, jsonCellText(value))
+	}
+	return keys, values, nil
+}
+
+// jsonCellText converts a raw JSON value to cell text
+func jsonCellText(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	if string(value) == "null" {
+		return ""
+	}
+	return string(value)
+}
+
+// ReadAllRows drains a RowReader into memory
+func ReadAllRows(rows RowReader) ([]string, [][]string, error) {
+	headers, err := rows.Headers()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var all [][]string
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			return headers, all, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		all = append(all, row)
+	}
+}
+
+// truncateText shortens text to at most width runes, marking the cut with an ellipsis
+func truncateText(text string, width int) string {
+	if getDisplayLength(text) <= width {
+		return text
+	}
+	runes := []rune(cleanText(text))
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// Stream writes the table to w while reading rows from the reader. Column
+// widths come from opts.Widths or from the first SampleSize rows; later rows
+// that do not fit are truncated.
+func (r *ASCIITableRenderer) Stream(w io.Writer, config TableConfig, rows RowReader, opts StreamOptions) error {
+	if len(config.Headers) == 0 {
+		headers, err := rows.Headers()
+		if err != nil {
+			return err
+		}
+		config.Headers = headers
+	}
+	if len(config.Headers) == 0 {
+		return ErrNoHeaders
+	}
+
+	if config.Pivot != nil {
+		return fmt.Errorf("pivot: %w", ErrNotStreamable)
+	}
+
+	p, err := newPipeline(config)
+	if err != nil {
+		return err
+	}
+	if err := p.streamable(); err != nil {
+		return err
+	}
+	config = p.columns(config)
+
+	sampleSize := opts.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = DefaultSampleSize
+	}
+
+	// Only peek at the first row when every column has a fixed width
+	fixed := len(opts.Widths) >= len(config.Headers)
+	for i := 0; fixed && i < len(config.Headers); i++ {
+		fixed = opts.Widths[i] > 0
+	}
+	if fixed {
+		sampleSize = 1
+	}
+
+	// Buffer the sample used for sizing
+	var sample []Row
+	done := false
+	for len(sample) < sampleSize {
+		row, err := p.next(rows)
+		if err == io.EOF {
+			done = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+		sample = append(sample, row)
+	}
+
+	config.Rows = sample
+	layout := newTableLayout(config)
+	colWidths := layout.widths
+	for i, width := range opts.Widths {
+		if i < len(colWidths) && width > 0 {
+			colWidths[i] = width + 2
+		}
+	}
+
+	// Nothing to stream: fall back to the regular empty-table rendering
+	if done && len(sample) == 0 {
+		return r.Render(w, config)
+	}
+
+	tw := &tableWriter{w: w}
+
+	r.writeBorder(tw, colWidths, r.Style.TopLeft, r.Style.TopJoin, r.Style.TopRight)
+	r.writeHeader(tw, config, layout)
+	separator := r.borderLine(colWidths, r.Style.LeftJoin, r.Style.Cross, r.Style.RightJoin)
+	tw.WriteString(separator)
+
+	// Read one row ahead so the last row is followed by the bottom border
+	next := func() (Row, error) {
+		if len(sample) > 0 {
+			row := sample[0]
+			sample = sample[1:]
+			return row, nil
+		}
+		if done {
+			return Row{}, io.EOF
+		}
+		return p.next(rows)
+	}
+
+	row, err := next()
+	for err == nil && tw.err == nil {
+		r.writeRow(tw, config, row, layout)
+
+		row, err = next()
+		if err == nil {
+			tw.WriteString(separator)
+		}
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	r.writeBorder(tw, colWidths, r.Style.BottomLeft, r.Style.BottomJoin, r.Style.BottomRight)
+
+	return tw.err
+}
+
+// next reads the next row that passes the filter and applies the per-row options
+func (p *pipeline) next(rows RowReader) (Row, error) {
+	for {
+		texts, err := rows.Next()
+		if err != nil {
+			return Row{}, err
+		}
+
+		row, err := p.compute(TextRow(texts...))
+		if err != nil {
+			return Row{}, err
+		}
+		keep, err := p.keep(row)
+		if err != nil {
+			return Row{}, err
+		}
+		if keep {
+			return p.row(row), nil
+		}
+	}
+}
+
+// isNDJSONPath reports whether a data file name looks like newline-delimited JSON
+func isNDJSONPath(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".ndjson", ".jsonl", ".json"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRowReader returns a reader for the given data format ("csv" or "ndjson").
+// An empty format is inferred from the file name, defaulting to CSV.
+func NewRowReader(r io.Reader, format, name string, headers []string) (RowReader, error) {
+	if format == "" {
+		format = "csv"
+		if isNDJSONPath(name) {
+			format = "ndjson"
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "csv":
+		return NewCSVReader(r, headers), nil
+	case "ndjson", "jsonl":
+		return NewNDJSONReader(r, headers), nil
+	default:
+		return nil, fmt.Errorf("unknown data format: %s. Available formats: [csv ndjson]", format)
+	}
+}
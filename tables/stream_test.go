@@ -0,0 +1,183 @@
+package tables
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// benchmarkCSV builds a CSV document with the given number of data rows
+func benchmarkCSV(rows int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("Database,Status,Connections,Response Time\n")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&buf, "db-%d,Online,%d/100,%dms\n", i, i%100, i%250)
+	}
+	return buf.Bytes()
+}
+
+func benchmarkRenderer(b *testing.B) *ASCIITableRenderer {
+	renderer, err := GetRenderer("single-line-full")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return renderer.(*ASCIITableRenderer)
+}
+
+func benchmarkRender(b *testing.B, rows int) {
+	data := benchmarkCSV(rows)
+	renderer := benchmarkRenderer(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		headers, all, err := ReadAllRows(NewCSVReader(bytes.NewReader(data), nil))
+		if err != nil {
+			b.Fatal(err)
+		}
+		config := TableConfig{Headers: headers, Rows: TextRows(all)}
+		if err := renderer.Render(io.Discard, config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkStream(b *testing.B, rows int) {
+	data := benchmarkCSV(rows)
+	renderer := benchmarkRenderer(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		reader := NewCSVReader(bytes.NewReader(data), nil)
+		if err := renderer.Stream(io.Discard, TableConfig{}, reader, StreamOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRender1K(b *testing.B)   { benchmarkRender(b, 1000) }
+func BenchmarkRender100K(b *testing.B) { benchmarkRender(b, 100000) }
+func BenchmarkStream1K(b *testing.B)   { benchmarkStream(b, 1000) }
+func BenchmarkStream100K(b *testing.B) { benchmarkStream(b, 100000) }
+
+func TestStreamSeparatorBeyondSample(t *testing.T) {
+	tests := []struct {
+		name  string
+		align string
+		opts  StreamOptions
+		want  []string
+	}{
+		{"decimal sample", "decimal", StreamOptions{SampleSize: 1}, []string{"│ 1.5 │", "│ 12… │"}},
+		{"decimal fixed width", "decimal", StreamOptions{Widths: []int{6}}, []string{"│    1.5 │", "│ 12345… │"}},
+		{"char sample", "char::", StreamOptions{SampleSize: 1}, []string{"│ a:b │", "│ lo… │"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := "X\n1.5\n12345.25\n"
+			if strings.HasPrefix(tt.align, "char:") {
+				rows = "X\na:b\nlong:value\n"
+			}
+			reader := NewCSVReader(strings.NewReader(rows), nil)
+			config := TableConfig{Alignment: []string{tt.align}}
+
+			var out bytes.Buffer
+			if err := testRenderer(t).Stream(&out, config, reader, tt.opts); err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(out.String(), line) {
+					t.Errorf("output has no line containing %q:\n%s", line, out.String())
+				}
+			}
+		})
+	}
+}
+
+func testRenderer(t *testing.T) *ASCIITableRenderer {
+	renderer, err := GetRenderer("single-line-full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return renderer.(*ASCIITableRenderer)
+}
+
+func TestRowReaderHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		data        string
+		headers     []string
+		wantHeaders []string
+		wantRows    [][]string
+	}{
+		{
+			name:        "csv from file",
+			format:      "csv",
+			data:        "A,B\n1,2\n",
+			wantHeaders: []string{"A", "B"},
+			wantRows:    [][]string{{"1", "2"}},
+		},
+		{
+			name:        "csv configured",
+			format:      "csv",
+			data:        "1,2\n3,4\n",
+			headers:     []string{"A", "B"},
+			wantHeaders: []string{"A", "B"},
+			wantRows:    [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name:        "ndjson arrays from file",
+			format:      "ndjson",
+			data:        "[\"A\",\"B\"]\n[1,2]\n",
+			wantHeaders: []string{"A", "B"},
+			wantRows:    [][]string{{"1", "2"}},
+		},
+		{
+			name:        "ndjson arrays configured",
+			format:      "ndjson",
+			data:        "[1,2]\n[3,4]\n",
+			headers:     []string{"A", "B"},
+			wantHeaders: []string{"A", "B"},
+			wantRows:    [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name:        "ndjson objects from file",
+			format:      "ndjson",
+			data:        "{\"B\":2,\"A\":1}\n{\"A\":3,\"B\":4}\n",
+			wantHeaders: []string{"B", "A"},
+			wantRows:    [][]string{{"2", "1"}, {"4", "3"}},
+		},
+		{
+			name:        "ndjson objects configured",
+			format:      "ndjson",
+			data:        "{\"B\":2,\"A\":1}\n{\"A\":3,\"C\":5}\n",
+			headers:     []string{"A", "B"},
+			wantHeaders: []string{"A", "B"},
+			wantRows:    [][]string{{"1", "2"}, {"3", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewRowReader(strings.NewReader(tt.data), tt.format, "", tt.headers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			headers, rows, err := ReadAllRows(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(headers, tt.wantHeaders) {
+				t.Errorf("headers = %q, want %q", headers, tt.wantHeaders)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", rows, tt.wantRows)
+			}
+		})
+	}
+}
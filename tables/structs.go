@@ -0,0 +1,175 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// structColumn describes a struct field rendered as a table column
+type structColumn struct {
+	index  []int
+	header string
+	align  string
+	format string
+}
+
+// FromStructs builds a table from a slice (or array) of structs, or pointers
+// to structs. Columns follow field order and are configured with tags of the
+// form `table:"Header,align=right,format=%.2f"`; `table:"-"` skips a field.
+// A printf format is applied to each value, any other format (such as
+// "number:2" or "bytes") becomes the column format.
+// Nested structs are flattened with their header as prefix ("Parent.Child"),
+// embedded structs are flattened without a prefix.
+func FromStructs(slice any) (*Table, error) {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("FromStructs expects a slice of structs, got %T", slice)
+	}
+
+	elemType := rv.Type().Elem()
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("FromStructs expects a slice of structs, got %T", slice)
+	}
+
+	columns, err := structColumns(elemType, nil, "", map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	table := NewTable()
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+		if col.align != "" {
+			table.Align(i, parseAlignment(col.align))
+		}
+		if col.format != "" && !isPrintfFormat(col.format) {
+			table.Format(i, col.format)
+		}
+	}
+	table.Headers(headers...)
+
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i)
+		values := make([]any, len(columns))
+		for j, col := range columns {
+			field, ok := fieldByIndex(elem, col.index)
+			if !ok {
+				continue
+			}
+			if isPrintfFormat(col.format) {
+				values[j] = Value(field.Interface(), WithFormat(col.format))
+			} else {
+				values[j] = field.Interface()
+			}
+		}
+		table.AddRow(values...)
+	}
+
+	return table, nil
+}
+
+// isPrintfFormat reports whether a tag format is a printf verb rather than a column format
+func isPrintfFormat(format string) bool {
+	return strings.HasPrefix(format, "%")
+}
+
+// structColumns lists the columns for a struct type, flattening nested structs.
+// visiting holds the struct types on the current path: a field referring back
+// to one of them (as in a linked list) is rendered as a value, not flattened.
+func structColumns(t reflect.Type, parent []int, prefix string, visiting map[reflect.Type]bool) ([]structColumn, error) {
+	var columns []structColumn
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("table")
+		if tag == "-" {
+			continue
+		}
+
+		col := structColumn{
+			index:  append(append([]int{}, parent...), i),
+			header: field.Name,
+		}
+
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			col.header = parts[0]
+		}
+		for _, opt := range parts[1:] {
+			key, value, _ := strings.Cut(opt, "=")
+			switch strings.TrimSpace(key) {
+			case "align":
+				col.align = value
+			case "format":
+				col.format = value
+			default:
+				return nil, fmt.Errorf("field %s: unknown table tag option %q", field.Name, key)
+			}
+		}
+
+		if isNestedStruct(field.Type) && !visiting[indirectType(field.Type)] {
+			nestedPrefix := prefix + col.header + "."
+			if field.Anonymous && parts[0] == "" {
+				nestedPrefix = prefix
+			}
+			nested, err := structColumns(indirectType(field.Type), col.index, nestedPrefix, visiting)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, nested...)
+			continue
+		}
+
+		col.header = prefix + col.header
+		columns = append(columns, col)
+	}
+
+	return columns, nil
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// isNestedStruct reports whether a field type should be flattened into columns
+func isNestedStruct(t reflect.Type) bool {
+	if t.Implements(stringerType) || reflect.PointerTo(t).Implements(stringerType) {
+		return false
+	}
+	t = indirectType(t)
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// fieldByIndex follows a field path, reporting false if it crosses a nil pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
@@ -0,0 +1,66 @@
+package tables
+
+import (
+	"reflect"
+	"testing"
+)
+
+type structTestNode struct {
+	Name string
+	Next *structTestNode
+}
+
+type structTestTree struct {
+	Label string
+	Left  *structTestTree `table:"L"`
+	Meta  structTestMeta
+}
+
+type structTestMeta struct {
+	Owner  string
+	Parent *structTestTree
+}
+
+func TestFromStructsSelfReferential(t *testing.T) {
+	tests := []struct {
+		name    string
+		slice   any
+		headers []string
+	}{
+		{"linked list", []structTestNode{{Name: "a"}}, []string{"Name", "Next"}},
+		{"pointer elements", []*structTestNode{{Name: "a"}}, []string{"Name", "Next"}},
+		{"indirect cycle", []structTestTree{{Label: "root"}}, []string{"Label", "L", "Meta.Owner", "Meta.Parent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := FromStructs(tt.slice)
+			if err != nil {
+				t.Fatalf("FromStructs: %v", err)
+			}
+			if got := table.Config().Headers; !reflect.DeepEqual(got, tt.headers) {
+				t.Errorf("headers = %q, want %q", got, tt.headers)
+			}
+		})
+	}
+}
+
+func TestFromStructsCyclicValue(t *testing.T) {
+	a := &structTestNode{Name: "a"}
+	a.Next = a
+
+	table, err := FromStructs([]*structTestNode{a, {Name: "b"}})
+	if err != nil {
+		t.Fatalf("FromStructs: %v", err)
+	}
+	rows := table.Config().Rows
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if got := rows[0].Text(0); got != "a" {
+		t.Errorf("row 0 name = %q, want %q", got, "a")
+	}
+	if got := rows[1].Text(1); got != "" {
+		t.Errorf("nil Next = %q, want empty", got)
+	}
+}
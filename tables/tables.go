@@ -1,19 +1,45 @@
 package tables
 
 import (
-	"fmt"
+	"errors"
+	"io"
 	"strings"
+	"unicode/utf8"
 
 	"tablemaker/output"
 )
 
 // TableConfig represents the main configuration structure for ASCII tables
 type TableConfig struct {
-	Type      string            `json:"type"`
-	Name      string            `json:"name"`
-	Headers   []string          `json:"headers"`
-	Rows      [][]string        `json:"rows"`
-	Alignment []string          `json:"alignment,omitempty"`
+	Type      string   `json:"type"`
+	Name      string   `json:"name"`
+	Headers   []string `json:"headers"`
+	Rows      []Row    `json:"rows"`
+	Alignment []string `json:"alignment,omitempty"`
+	// HeaderAlignment overrides Alignment for the header row
+	HeaderAlignment []string `json:"header_alignment,omitempty"`
+	Format          []string `json:"format,omitempty"`
+	// ComputedColumns adds columns derived from each row
+	ComputedColumns []ComputedColumn `json:"computed_columns,omitempty"`
+	// Columns selects, orders and renames the columns to display
+	Columns []ColumnSpec `json:"columns,omitempty"`
+	// Filter keeps the rows matching an expression, e.g. `Status != "Offline"`
+	Filter string `json:"filter,omitempty"`
+	// Rules style the cells of rows matching an expression
+	Rules []Rule `json:"rules,omitempty"`
+	// Sort orders rows by one or more columns, e.g. "Connections:desc,Database"
+	Sort string `json:"sort,omitempty"`
+	// GroupBy clusters rows by a column under a section row per value
+	GroupBy string `json:"group_by,omitempty"`
+	// Subtotals adds a row per group aggregating the listed columns, e.g.
+	// "Connections" (sum) or "Response Time:avg"
+	Subtotals []string `json:"subtotals,omitempty"`
+	// Pivot reshapes long-format rows into a cross-tab before the other
+	// options are applied, so they refer to the pivoted columns
+	Pivot *PivotSpec `json:"pivot,omitempty"`
+	// Transpose renders each record vertically as field/value rows
+	Transpose bool              `json:"transpose,omitempty"`
+	EmptyText string            `json:"empty_text,omitempty"`
 	PNG       *output.PNGConfig `json:"png,omitempty"`
 }
 
@@ -24,8 +50,26 @@ const (
 	AlignLeft   AlignmentType = "left"
 	AlignCenter AlignmentType = "center"
 	AlignRight  AlignmentType = "right"
+	// AlignDecimal lines up the decimal points of a column
+	AlignDecimal AlignmentType = "decimal"
 )
 
+// AlignChar returns an alignment that lines up a column on the given character
+func AlignChar(sep string) AlignmentType {
+	return AlignmentType("char:" + sep)
+}
+
+// separator returns the character a column is aligned on, if any
+func (a AlignmentType) separator() (string, bool) {
+	if a == AlignDecimal {
+		return ".", true
+	}
+	if sep, ok := strings.CutPrefix(string(a), "char:"); ok && sep != "" {
+		return sep, true
+	}
+	return "", false
+}
+
 // TableStyle defines the characters used for table borders
 type TableStyle struct {
 	TopLeft     string
@@ -39,9 +83,32 @@ type TableStyle struct {
 	LeftJoin    string
 	RightJoin   string
 	Cross       string
+
+	// Section characters draw the lines around spanning section rows.
+	// Styles that leave them empty use the regular border characters.
+	SectionLeft       string
+	SectionRight      string
+	SectionHorizontal string
+	SectionTopJoin    string
+	SectionBottomJoin string
 }
 
-// Predefined table styles
+// sectionStyle returns the style with its section characters filled in
+func (s TableStyle) sectionStyle() TableStyle {
+	fallback := func(value *string, def string) {
+		if *value == "" {
+			*value = def
+		}
+	}
+	fallback(&s.SectionLeft, s.LeftJoin)
+	fallback(&s.SectionRight, s.RightJoin)
+	fallback(&s.SectionHorizontal, s.Horizontal)
+	fallback(&s.SectionTopJoin, s.TopJoin)
+	fallback(&s.SectionBottomJoin, s.BottomJoin)
+	return s
+}
+
+// Predefined table styles, registered in DefaultRegistry
 var tableStyles = map[string]TableStyle{
 	"single-line-full": {
 		TopLeft:     "┌",
@@ -55,6 +122,12 @@ var tableStyles = map[string]TableStyle{
 		LeftJoin:    "├",
 		RightJoin:   "┤",
 		Cross:       "┼",
+
+		SectionLeft:       "╞",
+		SectionRight:      "╡",
+		SectionHorizontal: "═",
+		SectionTopJoin:    "╤",
+		SectionBottomJoin: "╧",
 	},
 	"double-line-full": {
 		TopLeft:     "╔",
@@ -68,17 +141,60 @@ var tableStyles = map[string]TableStyle{
 		LeftJoin:    "╠",
 		RightJoin:   "╣",
 		Cross:       "╬",
+
+		SectionLeft:       "╟",
+		SectionRight:      "╢",
+		SectionHorizontal: "─",
+		SectionTopJoin:    "╥",
+		SectionBottomJoin: "╨",
 	},
 }
 
 // TableRenderer interface for different table styles
 type TableRenderer interface {
-	Render(config TableConfig) string
+	Render(w io.Writer, config TableConfig) error
+}
+
+// ErrNoHeaders is returned when a table has no columns to render
+var ErrNoHeaders = errors.New("table has no headers")
+
+// RenderString renders a table with the given renderer and returns it as a string
+func RenderString(r TableRenderer, config TableConfig) (string, error) {
+	var result strings.Builder
+	if err := r.Render(&result, config); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// StyledRenderer is implemented by renderers whose text output can be drawn
+// as an image. RenderStyled writes the text without escape sequences and
+// returns the styles of its cells as spans.
+type StyledRenderer interface {
+	RenderStyled(w io.Writer, config TableConfig) ([]output.Span, error)
+}
+
+// RenderStyledString renders a table to a string together with the styles
+// of its cells. Renderers that are not StyledRenderers return no spans.
+func RenderStyledString(r TableRenderer, config TableConfig) (string, []output.Span, error) {
+	styled, ok := r.(StyledRenderer)
+	if !ok {
+		text, err := RenderString(r, config)
+		return text, nil, err
+	}
+	var result strings.Builder
+	spans, err := styled.RenderStyled(&result, config)
+	if err != nil {
+		return "", nil, err
+	}
+	return result.String(), spans, nil
 }
 
 // ASCIITableRenderer renders tables with configurable ASCII styles
 type ASCIITableRenderer struct {
 	Style TableStyle
+	// ANSI draws cell styles with terminal escape sequences
+	ANSI bool
 }
 
 func parseAlignment(align string) AlignmentType {
@@ -87,9 +203,16 @@ func parseAlignment(align string) AlignmentType {
 		return AlignCenter
 	case "right":
 		return AlignRight
-	default:
-		return AlignLeft
+	case "decimal":
+		return AlignDecimal
 	}
+
+	// char:X keeps the separator exactly as written
+	if len(align) > 5 && strings.EqualFold(align[:5], "char:") {
+		return AlignChar(align[5:])
+	}
+
+	return AlignLeft
 }
 
 func cleanText(text string) string {
@@ -101,6 +224,57 @@ func getDisplayLength(text string) int {
 	return len([]rune(cleanText(text)))
 }
 
+// anchorWidths holds the widest text before and from the alignment
+// character in a column aligned on a separator
+type anchorWidths struct {
+	left  int
+	right int
+}
+
+// splitOnSeparator splits cell text at the first separator, returning the
+// display widths of the part before it and of the separator and the rest
+func splitOnSeparator(text, sep string) (int, int) {
+	clean := cleanText(text)
+	idx := strings.Index(clean, sep)
+	if idx < 0 {
+		return getDisplayLength(clean), 0
+	}
+	return getDisplayLength(clean[:idx]), getDisplayLength(clean[idx:])
+}
+
+// anchorSet holds the anchor widths of a column for each separator used in it
+type anchorSet map[string]anchorWidths
+
+// calculateAnchors measures separator positions for cells aligned on a character
+func calculateAnchors(config TableConfig) []anchorSet {
+	anchors := make([]anchorSet, len(config.Headers))
+	for _, row := range config.Rows {
+		for i := range anchors {
+			sep, ok := getCellAlignment(config, row, i).separator()
+			if !ok {
+				continue
+			}
+			if anchors[i] == nil {
+				anchors[i] = anchorSet{}
+			}
+			left, right := splitOnSeparator(row.Text(i), sep)
+			anchor := anchors[i][sep]
+			anchor.left = max(anchor.left, left)
+			anchor.right = max(anchor.right, right)
+			anchors[i][sep] = anchor
+		}
+	}
+	return anchors
+}
+
+// alignOnSeparator pads text so its separator sits at the column's anchor.
+// Text wider than the anchor on either side, as in streamed rows that were
+// not sampled, is not padded on that side.
+func alignOnSeparator(text, sep string, anchor anchorWidths) string {
+	left, right := splitOnSeparator(text, sep)
+	return strings.Repeat(" ", max(anchor.left-left, 0)) + cleanText(text) + strings.Repeat(" ", max(anchor.right-right, 0))
+}
+
 func calculateColumnWidths(config TableConfig) []int {
 	if len(config.Headers) == 0 {
 		return []int{}
@@ -108,16 +282,23 @@ func calculateColumnWidths(config TableConfig) []int {
 
 	colWidths := make([]int, len(config.Headers))
 
+	// Cells aligned on a separator need room for the widest part on each side
+	for i, set := range calculateAnchors(config) {
+		for _, anchor := range set {
+			colWidths[i] = max(colWidths[i], anchor.left+anchor.right)
+		}
+	}
+
 	// Check header widths using display length
 	for i, header := range config.Headers {
-		colWidths[i] = getDisplayLength(header)
+		colWidths[i] = max(colWidths[i], getDisplayLength(header))
 	}
 
 	// Check all row cell widths using display length
 	for _, row := range config.Rows {
-		for i, cell := range row {
+		for i, cell := range row.Cells {
 			if i < len(colWidths) {
-				cellLen := getDisplayLength(cell)
+				cellLen := getDisplayLength(cell.Text)
 				if cellLen > colWidths[i] {
 					colWidths[i] = cellLen
 				}
@@ -130,6 +311,16 @@ func calculateColumnWidths(config TableConfig) []int {
 		colWidths[i] += 2
 	}
 
+	// Make room for section titles by widening the last column
+	for _, row := range config.Rows {
+		if row.Section == "" {
+			continue
+		}
+		if extra := getDisplayLength(row.Section) + 2 - spanWidth(colWidths); extra > 0 {
+			colWidths[len(colWidths)-1] += extra
+		}
+	}
+
 	return colWidths
 }
 
@@ -140,6 +331,32 @@ func getColumnAlignment(config TableConfig, columnIndex int) AlignmentType {
 	return AlignLeft
 }
 
+// getHeaderAlignment resolves the alignment of a header cell: header
+// alignment > column alignment > default. Headers are never aligned on a
+// separator, which is measured on data rows only: they are right-aligned
+// instead.
+func getHeaderAlignment(config TableConfig, columnIndex int) AlignmentType {
+	alignment := getColumnAlignment(config, columnIndex)
+	if columnIndex < len(config.HeaderAlignment) && config.HeaderAlignment[columnIndex] != "" {
+		alignment = parseAlignment(config.HeaderAlignment[columnIndex])
+	}
+	if _, ok := alignment.separator(); ok {
+		return AlignRight
+	}
+	return alignment
+}
+
+// getCellAlignment resolves the alignment of a data cell: cell > row > column > default
+func getCellAlignment(config TableConfig, row Row, columnIndex int) AlignmentType {
+	if columnIndex < len(row.Cells) && row.Cells[columnIndex].Align != "" {
+		return parseAlignment(row.Cells[columnIndex].Align)
+	}
+	if row.Align != "" {
+		return parseAlignment(row.Align)
+	}
+	return getColumnAlignment(config, columnIndex)
+}
+
 func formatCellContent(content string, width int, alignment AlignmentType) string {
 	cleanContent := cleanText(content)
 	contentLen := getDisplayLength(content)
@@ -152,12 +369,16 @@ func formatCellContent(content string, width int, alignment AlignmentType) strin
 	// Calculate total spaces needed to fill the width
 	spacesNeeded := width - contentLen
 
-	switch alignment {
-	case AlignCenter:
+	// Cells aligned on a separator arrive pre-padded by alignOnSeparator
+	// and are right-aligned as a block
+	_, anchored := alignment.separator()
+
+	switch {
+	case alignment == AlignCenter:
 		leftSpaces := spacesNeeded / 2
 		rightSpaces := spacesNeeded - leftSpaces
 		return strings.Repeat(" ", leftSpaces) + cleanContent + strings.Repeat(" ", rightSpaces)
-	case AlignRight:
+	case alignment == AlignRight || anchored:
 		leftSpaces := spacesNeeded - 1
 		return strings.Repeat(" ", leftSpaces) + cleanContent + " "
 	default: // AlignLeft
@@ -166,102 +387,268 @@ func formatCellContent(content string, width int, alignment AlignmentType) strin
 	}
 }
 
-func (r *ASCIITableRenderer) Render(config TableConfig) string {
-	if len(config.Rows) == 0 || len(config.Headers) == 0 {
-		return ""
+// tableWriter wraps an io.Writer and keeps the first write error. When
+// spans is set it also tracks the line and rune column written so far.
+type tableWriter struct {
+	w         io.Writer
+	err       error
+	spans     *[]output.Span
+	line, col int
+}
+
+func (tw *tableWriter) WriteString(s string) {
+	if tw.err != nil {
+		return
+	}
+	_, tw.err = io.WriteString(tw.w, s)
+	if tw.spans == nil {
+		return
+	}
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		tw.line += strings.Count(s, "\n")
+		tw.col = 0
+		s = s[i+1:]
+	}
+	tw.col += utf8.RuneCountInString(s)
+}
+
+// writeStyled writes text, recording its style as a span
+func (tw *tableWriter) writeStyled(text string, style *CellStyle) {
+	start := tw.col
+	tw.WriteString(text)
+	if tw.spans != nil && style != nil {
+		*tw.spans = append(*tw.spans, output.Span{Line: tw.line, Start: start, End: tw.col, Style: style.textStyle()})
 	}
+}
 
-	colWidths := calculateColumnWidths(config)
-	var result strings.Builder
+// borderLine builds a horizontal border line using the given corner and join characters
+func (r *ASCIITableRenderer) borderLine(colWidths []int, left, join, right string) string {
+	return drawLine(colWidths, left, r.Style.Horizontal, join, right)
+}
 
-	// Top border
-	result.WriteString(r.Style.TopLeft)
+// drawLine builds a horizontal line. An empty join continues the line
+// across column boundaries.
+func drawLine(colWidths []int, left, horizontal, join, right string) string {
+	if join == "" {
+		join = horizontal
+	}
+	var line strings.Builder
+	line.WriteString(left)
 	for i, width := range colWidths {
-		result.WriteString(strings.Repeat(r.Style.Horizontal, width))
+		line.WriteString(strings.Repeat(horizontal, width))
 		if i < len(colWidths)-1 {
-			result.WriteString(r.Style.TopJoin)
+			line.WriteString(join)
 		}
 	}
-	result.WriteString(r.Style.TopRight + "\n")
+	line.WriteString(right + "\n")
+	return line.String()
+}
 
-	// Header row
-	result.WriteString(r.Style.Vertical)
-	for i, header := range config.Headers {
-		alignment := getColumnAlignment(config, i)
-		formattedCell := formatCellContent(header, colWidths[i], alignment)
-		result.WriteString(formattedCell + r.Style.Vertical)
+// writeBorder writes a horizontal border line using the given corner and join characters
+func (r *ASCIITableRenderer) writeBorder(tw *tableWriter, colWidths []int, left, join, right string) {
+	tw.WriteString(r.borderLine(colWidths, left, join, right))
+}
+
+// tableLayout holds the column widths and separator positions used to draw rows
+type tableLayout struct {
+	widths  []int
+	anchors []anchorSet
+}
+
+func newTableLayout(config TableConfig) tableLayout {
+	return tableLayout{
+		widths:  calculateColumnWidths(config),
+		anchors: calculateAnchors(config),
 	}
-	result.WriteString("\n")
+}
 
-	// Header separator
-	result.WriteString(r.Style.LeftJoin)
-	for i, width := range colWidths {
-		result.WriteString(strings.Repeat(r.Style.Horizontal, width))
-		if i < len(colWidths)-1 {
-			result.WriteString(r.Style.Cross)
+// writeHeader writes the header row
+func (r *ASCIITableRenderer) writeHeader(tw *tableWriter, config TableConfig, layout tableLayout) {
+	aligns := make([]AlignmentType, len(layout.widths))
+	for i := range aligns {
+		aligns[i] = getHeaderAlignment(config, i)
+	}
+	r.writeCells(tw, config.Headers, aligns, nil, layout)
+}
+
+// writeRow writes a data row using the resolved alignment of each cell
+func (r *ASCIITableRenderer) writeRow(tw *tableWriter, config TableConfig, row Row, layout tableLayout) {
+	aligns := make([]AlignmentType, len(layout.widths))
+	styles := make([]*CellStyle, len(layout.widths))
+	for i := range aligns {
+		aligns[i] = getCellAlignment(config, row, i)
+		if i < len(row.Cells) {
+			styles[i] = row.Cells[i].Style
 		}
 	}
-	result.WriteString(r.Style.RightJoin + "\n")
+	r.writeCells(tw, row.Texts(), aligns, styles, layout)
+}
 
-	// Data rows
-	for rowIdx, row := range config.Rows {
-		result.WriteString(r.Style.Vertical)
-		for i, cell := range row {
-			if i < len(colWidths) {
-				alignment := getColumnAlignment(config, i)
-				formattedCell := formatCellContent(cell, colWidths[i], alignment)
-				result.WriteString(formattedCell + r.Style.Vertical)
-			}
+// writeCells writes a single row of cells, padding missing cells with blanks
+// and truncating cells wider than their column. Styles are drawn over the
+// whole cell when ANSI output is enabled.
+func (r *ASCIITableRenderer) writeCells(tw *tableWriter, cells []string, aligns []AlignmentType, styles []*CellStyle, layout tableLayout) {
+	tw.WriteString(r.Style.Vertical)
+	for i, width := range layout.widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
 		}
-		result.WriteString("\n")
-
-		// Row separator (except for last row)
-		if rowIdx < len(config.Rows)-1 {
-			result.WriteString(r.Style.LeftJoin)
-			for i, width := range colWidths {
-				result.WriteString(strings.Repeat(r.Style.Horizontal, width))
-				if i < len(colWidths)-1 {
-					result.WriteString(r.Style.Cross)
-				}
+
+		if sep, ok := aligns[i].separator(); ok {
+			cell = alignOnSeparator(cell, sep, layout.anchors[i][sep])
+		}
+
+		content := formatCellContent(truncateText(cell, width-2), width, aligns[i])
+		var style *CellStyle
+		if i < len(styles) {
+			style = styles[i]
+		}
+		if r.ANSI && style != nil {
+			if sgr := style.sgr(); sgr != "" {
+				content = sgr + content + "\x1b[0m"
 			}
-			result.WriteString(r.Style.RightJoin + "\n")
 		}
+		tw.writeStyled(content, style)
+		tw.WriteString(r.Style.Vertical)
 	}
+	tw.WriteString("\n")
+}
 
-	// Bottom border
-	result.WriteString(r.Style.BottomLeft)
-	for i, width := range colWidths {
-		result.WriteString(strings.Repeat(r.Style.Horizontal, width))
-		if i < len(colWidths)-1 {
-			result.WriteString(r.Style.BottomJoin)
+// writeSection writes a section row spanning all columns
+func (r *ASCIITableRenderer) writeSection(tw *tableWriter, row Row, colWidths []int) {
+	align := AlignLeft
+	if row.Align != "" {
+		align = parseAlignment(row.Align)
+	}
+	width := spanWidth(colWidths)
+	tw.WriteString(r.Style.Vertical + formatCellContent(truncateText(row.Section, width-2), width, align) + r.Style.Vertical + "\n")
+}
+
+// spanWidth returns the inner width of a row spanning all columns
+func spanWidth(colWidths []int) int {
+	width := len(colWidths) - 1
+	for _, w := range colWidths {
+		width += w
+	}
+	return width
+}
+
+// Render writes the table to w. A table without rows renders its header
+// only, followed by a spanning placeholder row when EmptyText is set.
+func (r *ASCIITableRenderer) Render(w io.Writer, config TableConfig) error {
+	return r.render(&tableWriter{w: w}, config)
+}
+
+// RenderStyled writes the table to w without ANSI escape sequences and
+// returns the styles of its cells
+func (r *ASCIITableRenderer) RenderStyled(w io.Writer, config TableConfig) ([]output.Span, error) {
+	plain := *r
+	plain.ANSI = false
+	var spans []output.Span
+	err := plain.render(&tableWriter{w: w, spans: &spans}, config)
+	return spans, err
+}
+
+func (r *ASCIITableRenderer) render(tw *tableWriter, config TableConfig) error {
+	if len(config.Headers) == 0 {
+		return ErrNoHeaders
+	}
+
+	config, err := Prepare(config)
+	if err != nil {
+		return err
+	}
+
+	layout := newTableLayout(config)
+	colWidths := layout.widths
+	empty := len(config.Rows) == 0
+
+	// Make room for the placeholder by widening the last column
+	if empty && config.EmptyText != "" {
+		if extra := getDisplayLength(config.EmptyText) + 2 - spanWidth(colWidths); extra > 0 {
+			colWidths[len(colWidths)-1] += extra
 		}
 	}
-	result.WriteString(r.Style.BottomRight + "\n")
 
-	return result.String()
+	r.writeBorder(tw, colWidths, r.Style.TopLeft, r.Style.TopJoin, r.Style.TopRight)
+	r.writeHeader(tw, config, layout)
+
+	if empty {
+		if config.EmptyText == "" {
+			r.writeBorder(tw, colWidths, r.Style.BottomLeft, r.Style.BottomJoin, r.Style.BottomRight)
+			return tw.err
+		}
+
+		// Close the columns and render the placeholder across the full width
+		r.writeBorder(tw, colWidths, r.Style.LeftJoin, r.Style.BottomJoin, r.Style.RightJoin)
+		span := []int{spanWidth(colWidths)}
+		tw.WriteString(r.Style.Vertical + formatCellContent(config.EmptyText, span[0], AlignCenter) + r.Style.Vertical + "\n")
+		r.writeBorder(tw, span, r.Style.BottomLeft, "", r.Style.BottomRight)
+		return tw.err
+	}
+
+	// Section rows close the columns above them and open them again below,
+	// using the style's section characters
+	section := r.Style.sectionStyle()
+	separator := r.borderLine(colWidths, r.Style.LeftJoin, r.Style.Cross, r.Style.RightJoin)
+	closing := drawLine(colWidths, section.SectionLeft, section.SectionHorizontal, section.SectionBottomJoin, section.SectionRight)
+	opening := drawLine(colWidths, section.SectionLeft, section.SectionHorizontal, section.SectionTopJoin, section.SectionRight)
+	between := drawLine(colWidths, section.SectionLeft, section.SectionHorizontal, "", section.SectionRight)
+
+	// The header counts as a regular row for the line below it
+	prevSection := false
+	for _, row := range config.Rows {
+		isSection := row.Section != ""
+		switch {
+		case prevSection && isSection:
+			tw.WriteString(between)
+		case prevSection:
+			tw.WriteString(opening)
+		case isSection:
+			tw.WriteString(closing)
+		default:
+			tw.WriteString(separator)
+		}
+
+		if isSection {
+			r.writeSection(tw, row, colWidths)
+		} else {
+			r.writeRow(tw, config, row, layout)
+		}
+		prevSection = isSection
+	}
+
+	if prevSection {
+		r.writeBorder(tw, colWidths, r.Style.BottomLeft, "", r.Style.BottomRight)
+	} else {
+		r.writeBorder(tw, colWidths, r.Style.BottomLeft, r.Style.BottomJoin, r.Style.BottomRight)
+	}
+
+	return tw.err
 }
 
+// GetRenderer returns a renderer for the table type from the default registry
 func GetRenderer(tableType string) (TableRenderer, error) {
-	style, exists := tableStyles[strings.ToLower(tableType)]
-	if !exists {
-		return nil, fmt.Errorf("unknown table type: %s. Available types: %v",
-			tableType, getAvailableTypes())
-	}
-	return &ASCIITableRenderer{Style: style}, nil
+	return DefaultRegistry.Get(tableType)
 }
 
+// GetAvailableTypes returns the table types in the default registry
 func GetAvailableTypes() []string {
-	return getAvailableTypes()
+	return DefaultRegistry.Types()
 }
 
-func getAvailableTypes() []string {
-	types := make([]string, 0, len(tableStyles))
-	for typeName := range tableStyles {
-		types = append(types, typeName)
-	}
-	return types
+// RegisterTableStyle registers a border style in the default registry
+func RegisterTableStyle(name string, style TableStyle) {
+	DefaultRegistry.RegisterStyle(name, style)
 }
 
-func RegisterTableStyle(name string, style TableStyle) {
-	tableStyles[strings.ToLower(name)] = style
+// RegisterRenderer registers a renderer factory in the default registry
+func RegisterRenderer(name string, factory RendererFactory) {
+	DefaultRegistry.Register(name, factory)
+}
+
+// UnregisterRenderer removes a table type from the default registry
+func UnregisterRenderer(name string) bool {
+	return DefaultRegistry.Unregister(name)
 }
@@ -0,0 +1,183 @@
+package tables
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// renderTable renders a config with the single-line-full style
+func renderTable(t *testing.T, config TableConfig) string {
+	t.Helper()
+	renderer, err := GetRenderer("single-line-full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := RenderString(renderer, config)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return out
+}
+
+func TestHeaderSeparatorAlignment(t *testing.T) {
+	tests := []struct {
+		name            string
+		alignment       []string
+		headerAlignment []string
+		rows            [][]string
+		want            []string
+	}{
+		{
+			name:            "decimal header",
+			headerAlignment: []string{"decimal"},
+			rows:            [][]string{{"1.5"}, {"123456.25"}},
+			want:            []string{"│      Time │", "│ 1.5       │"},
+		},
+		{
+			name:            "char header",
+			alignment:       []string{"char::"},
+			headerAlignment: []string{"char::"},
+			rows:            [][]string{{"a:b"}, {"abcdef:gh"}},
+			want:            []string{"│      Time │", "│      a:b  │", "│ abcdef:gh │"},
+		},
+		{
+			name:      "decimal column",
+			alignment: []string{"decimal"},
+			rows:      [][]string{{"1.5"}, {"123456.25"}},
+			want:      []string{"│      Time │", "│      1.5  │", "│ 123456.25 │"},
+		},
+		{
+			name:            "header wider than anchors",
+			headerAlignment: []string{"decimal"},
+			alignment:       []string{"decimal"},
+			rows:            [][]string{{"1.5"}},
+			want:            []string{"│ Time │", "│  1.5 │"},
+		},
+		{
+			name:            "header without rows",
+			headerAlignment: []string{"char:-"},
+			want:            []string{"│ Time │"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := renderTable(t, TableConfig{
+				Headers:         []string{"Time"},
+				Alignment:       tt.alignment,
+				HeaderAlignment: tt.headerAlignment,
+				Rows:            TextRows(tt.rows),
+			})
+			for _, line := range tt.want {
+				if !strings.Contains(out, line) {
+					t.Errorf("output has no line containing %q:\n%s", line, out)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilderOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *Table
+		want  []string
+	}{
+		{
+			name: "column format",
+			table: NewTable().Headers("Item", "Cost").
+				AddRow("Disk", 1234.5).
+				Format(1, "currency"),
+			want: []string{"│ Disk │ $1,234.50 │"},
+		},
+		{
+			name: "format skips earlier columns",
+			table: NewTable().Headers("Item", "Share", "Size").
+				AddRow("Disk", 0.25, 2048).
+				Format(2, "bytes"),
+			want: []string{"│ Disk │ 0.25  │ 2.0 KiB │"},
+		},
+		{
+			name: "column alignment",
+			table: NewTable().Headers("Item", "Count").
+				AddRow("Disk", 5).
+				Align(1, AlignRight),
+			want: []string{"│ Item │ Count │", "│ Disk │     5 │"},
+		},
+		{
+			name: "header alignment",
+			table: NewTable().Headers("Item", "C").
+				AddRow("Disk", 12345).
+				AddRow("Tape", 7).
+				Align(1, AlignRight).
+				HeaderAlign(1, AlignLeft),
+			want: []string{"│ Item │ C     │", "│ Tape │     7 │"},
+		},
+		{
+			name: "centered header",
+			table: NewTable().Headers("Item", "C").
+				AddRow("Disk", "12345").
+				HeaderAlign(1, AlignCenter),
+			want: []string{"│ Item │   C   │", "│ Disk │ 12345 │"},
+		},
+		{
+			name: "row alignment",
+			table: NewTable().Headers("Item", "Count").
+				AddRow("Disk", 5).
+				AddRow("Tape", 7).
+				AlignRow(1, AlignRight),
+			want: []string{"│ Disk │ 5     │", "│ Tape │     7 │"},
+		},
+		{
+			name: "cell options",
+			table: NewTable().Headers("Item", "Load").
+				AddRow("Disk", Value(0.5, WithFormat("%.3f"))).
+				AddRow("Tape", Value(0.25, WithAlign(AlignRight))),
+			want: []string{"│ Disk │ 0.500 │", "│ Tape │  0.25 │"},
+		},
+		{
+			name: "time format",
+			table: NewTable().Headers("Checked").
+				TimeFormat(time.Kitchen).
+				AddRow(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)).
+				AddRow(Value(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), WithFormat("2006-01-02"))),
+			want: []string{"│ 3:04AM     │", "│ 2024-01-02 │"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := tt.table.Render(&out); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(out.String(), line) {
+					t.Errorf("output has no line containing %q:\n%s", line, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestBuilderBold(t *testing.T) {
+	config := NewTable().Headers("Item").AddRow(Value("Disk", Bold())).AddRow(Value("", Bold())).Config()
+	if got := config.Rows[0].Cells[0].Text; got != "**Disk**" {
+		t.Errorf("bold cell = %q, want %q", got, "**Disk**")
+	}
+	if got := config.Rows[1].Cells[0].Text; got != "" {
+		t.Errorf("empty bold cell = %q, want empty", got)
+	}
+}
+
+func TestBuilderInvalidFormat(t *testing.T) {
+	table := NewTable().Headers("Cost").AddRow(5).Format(0, "money")
+	if err := table.Render(io.Discard); err == nil {
+		t.Fatal("Render succeeded, want error")
+	}
+	if got := table.String(); got != "" {
+		t.Errorf("String() = %q, want empty", got)
+	}
+}